@@ -0,0 +1,210 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceCloudstackISO() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCloudstackISORead,
+		Schema: map[string]*schema.Schema{
+			"filter": dataSourceFiltersSchema(),
+
+			"iso_filter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "executable",
+			},
+
+			"zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// Computed values
+			"iso_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"account": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"created": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"display_text": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"bootable": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"is_ready": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"os_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"tags": tagsSchema(),
+		},
+	}
+}
+
+func dataSourceCloudstackISORead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	p := cs.ISO.NewListIsosParams()
+	p.SetListall(true)
+	p.SetIsofilter(d.Get("iso_filter").(string))
+
+	if zone, ok := d.GetOk("zone"); ok {
+		zoneid, e := retrieveID(cs, "zone", zone.(string))
+		if e != nil {
+			return e.Error()
+		}
+		p.SetZoneid(zoneid)
+	}
+
+	csIsos, err := cs.ISO.ListIsos(p)
+	if err != nil {
+		return fmt.Errorf("Failed to list ISOs: %s", err)
+	}
+
+	filters := d.Get("filter")
+	var isos []*cloudstack.Iso
+
+	for _, i := range csIsos.Isos {
+		match, err := applyISOFilters(i, filters.(*schema.Set))
+		if err != nil {
+			return err
+		}
+
+		if match {
+			isos = append(isos, i)
+		}
+	}
+
+	if len(isos) == 0 {
+		return fmt.Errorf("No ISO is matching with the specified regex")
+	}
+
+	iso, err := latestISO(isos)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Selected ISO: %s\n", iso.Displaytext)
+
+	return isoDescriptionAttributes(d, iso)
+}
+
+func isoDescriptionAttributes(d *schema.ResourceData, iso *cloudstack.Iso) error {
+	d.SetId(iso.Id)
+	d.Set("iso_id", iso.Id)
+	d.Set("account", iso.Account)
+	d.Set("created", iso.Created)
+	d.Set("display_text", iso.Displaytext)
+	d.Set("name", iso.Name)
+	d.Set("bootable", iso.Bootable)
+	d.Set("is_ready", iso.Isready)
+	d.Set("os_type", iso.Ostypename)
+	d.Set("size", iso.Size)
+	d.Set("tags", tagsToMap(iso.Tags))
+
+	return nil
+}
+
+func latestISO(isos []*cloudstack.Iso) (*cloudstack.Iso, error) {
+	var latest time.Time
+	var iso *cloudstack.Iso
+
+	for _, i := range isos {
+		created, err := time.Parse("2006-01-02T15:04:05-0700", i.Created)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse creation date of an ISO: %s", err)
+		}
+
+		if created.After(latest) {
+			latest = created
+			iso = i
+		}
+	}
+
+	return iso, nil
+}
+
+func applyISOFilters(iso *cloudstack.Iso, filters *schema.Set) (bool, error) {
+	var isoJSON map[string]interface{}
+	i, _ := json.Marshal(iso)
+	err := json.Unmarshal(i, &isoJSON)
+	if err != nil {
+		return false, err
+	}
+
+	for _, f := range filters.List() {
+		m := f.(map[string]interface{})
+
+		r, err := regexp.Compile(m["value"].(string))
+		if err != nil {
+			return false, fmt.Errorf("Invalid regex: %s", err)
+		}
+		updatedName := strings.ReplaceAll(m["name"].(string), "_", "")
+		isoField, ok := isoJSON[updatedName].(string)
+		if !ok {
+			return false, fmt.Errorf("Invalid filter name: %s", m["name"])
+		}
+		if !r.MatchString(isoField) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
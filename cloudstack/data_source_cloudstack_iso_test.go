@@ -0,0 +1,74 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccISODataSource_basic(t *testing.T) {
+	if cloudStackISOURL == "" {
+		t.Skip("This test requires an ISO URL")
+	}
+
+	resourceName := "cloudstack_iso.foo"
+	datasourceName := "data.cloudstack_iso.foo"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccISODataSourceConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(datasourceName, "id", resourceName, "id"),
+					resource.TestCheckResourceAttrPair(datasourceName, "name", resourceName, "name"),
+					resource.TestCheckResourceAttr(datasourceName, "bootable", "true"),
+					resource.TestCheckResourceAttr(datasourceName, "tags.terraform-tag", "true"),
+				),
+			},
+		},
+	})
+}
+
+var testAccISODataSourceConfig_basic = fmt.Sprintf(`
+resource "cloudstack_iso" "foo" {
+  name = "terraform-iso-data"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+  tags = {
+    terraform-tag = "true"
+  }
+}
+
+data "cloudstack_iso" "foo" {
+  filter {
+    name = "name"
+    value = "^terraform-iso-data$"
+  }
+
+  depends_on = [
+    cloudstack_iso.foo
+  ]
+}`, cloudStackISOURL)
@@ -0,0 +1,55 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceCloudstackKubernetesClusterConfig() *schema.Resource {
+	s := kubeConfigSchema()
+	s["cluster_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceCloudstackKubernetesClusterConfigRead,
+		Schema: s,
+	}
+}
+
+func dataSourceCloudstackKubernetesClusterConfigRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	id := d.Get("cluster_id").(string)
+
+	config, err := getKubernetesClusterConfig(cs, id)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+	for k, v := range config {
+		d.Set(k, v)
+	}
+
+	return nil
+}
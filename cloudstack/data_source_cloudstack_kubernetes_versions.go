@@ -0,0 +1,225 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceCloudstackKubernetesVersions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCloudstackKubernetesVersionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"minimum_semantic_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"state": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Enabled", "Disabled"}, false),
+			},
+
+			"only_ready": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			// Computed values
+			"versions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"semantic_version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"zone_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"zone_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"iso_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"iso_state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"min_cpu": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"min_memory": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"supports_ha": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"supports_autoscaling": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"semantic_versions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceCloudstackKubernetesVersionsRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	p := cs.Kubernetes.NewListKubernetesSupportedVersionsParams()
+
+	if v, ok := d.GetOk("zone"); ok {
+		zoneid, e := retrieveID(cs, "zone", v.(string))
+		if e != nil {
+			return e.Error()
+		}
+		p.SetZoneid(zoneid)
+	}
+
+	if v, ok := d.GetOk("minimum_semantic_version"); ok {
+		p.SetMinimumsemanticversion(v.(string))
+	}
+
+	r, err := cs.Kubernetes.ListKubernetesSupportedVersions(p)
+	if err != nil {
+		return fmt.Errorf("Error listing Kubernetes versions: %s", err)
+	}
+
+	state := d.Get("state").(string)
+	onlyReady := d.Get("only_ready").(bool)
+
+	var versions []*cloudstack.KubernetesSupportedVersion
+	for _, v := range r.KubernetesSupportedVersions {
+		if state != "" && v.State != state {
+			continue
+		}
+		if onlyReady && v.Isostate != "Ready" {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	if err := sortKubernetesVersions(versions); err != nil {
+		return err
+	}
+
+	list := make([]interface{}, 0, len(versions))
+	names := make([]interface{}, 0, len(versions))
+	ids := make([]string, 0, len(versions))
+	for _, v := range versions {
+		list = append(list, map[string]interface{}{
+			"id":                   v.Id,
+			"name":                 v.Name,
+			"semantic_version":     v.Semanticversion,
+			"state":                v.State,
+			"zone_id":              v.Zoneid,
+			"zone_name":            v.Zonename,
+			"iso_id":               v.Isoid,
+			"iso_state":            v.Isostate,
+			"min_cpu":              v.Mincpunumber,
+			"min_memory":           v.Minmemory,
+			"supports_ha":          v.Supportsha,
+			"supports_autoscaling": v.Supportsautoscaling,
+		})
+		names = append(names, v.Semanticversion)
+		ids = append(ids, v.Id)
+	}
+
+	d.SetId(fmt.Sprintf("%d", schema.HashString(strings.Join(ids, ","))))
+	d.Set("versions", list)
+	d.Set("semantic_versions", names)
+
+	return nil
+}
+
+// sortKubernetesVersions sorts the versions by their semantic version, from
+// the oldest to the newest version.
+func sortKubernetesVersions(versions []*cloudstack.KubernetesSupportedVersion) error {
+	parsed := make(map[string]*version.Version, len(versions))
+	for _, v := range versions {
+		sv, err := version.NewVersion(v.Semanticversion)
+		if err != nil {
+			return fmt.Errorf(
+				"Invalid semantic version %s of Kubernetes version %s: %s", v.Semanticversion, v.Name, err)
+		}
+		parsed[v.Id] = sv
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return parsed[versions[i].Id].LessThan(parsed[versions[j].Id])
+	})
+
+	return nil
+}
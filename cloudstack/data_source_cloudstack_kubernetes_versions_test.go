@@ -0,0 +1,86 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccCloudStackKubernetesVersionsDataSource_basic(t *testing.T) {
+	checkCKSEnabled(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKubernetesVersionsDataSourceConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.cloudstack_kubernetes_versions.foo", "semantic_versions.0", "1.23.3"),
+					resource.TestCheckResourceAttrPair(
+						"data.cloudstack_kubernetes_versions.foo", "versions.0.id",
+						"cloudstack_kubernetes_version.foo", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestSortKubernetesVersions(t *testing.T) {
+	versions := []*cloudstack.KubernetesSupportedVersion{
+		{Id: "a", Semanticversion: "1.24.10"},
+		{Id: "b", Semanticversion: "1.24.2"},
+		{Id: "c", Semanticversion: "1.9.0"},
+		{Id: "d", Semanticversion: "1.25.0"},
+	}
+
+	if err := sortKubernetesVersions(versions); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"1.9.0", "1.24.2", "1.24.10", "1.25.0"}
+	for i, v := range versions {
+		if v.Semanticversion != expected[i] {
+			t.Fatalf("expected version %d to be %s, got %s", i, expected[i], v.Semanticversion)
+		}
+	}
+
+	invalid := []*cloudstack.KubernetesSupportedVersion{{Id: "e", Semanticversion: "latest"}}
+	if err := sortKubernetesVersions(invalid); err == nil {
+		t.Fatalf("expected an error for an invalid semantic version")
+	}
+}
+
+const testAccKubernetesVersionsDataSourceConfig_basic = `
+resource "cloudstack_kubernetes_version" "foo" {
+  semantic_version      = "1.23.3"
+  url                   = "http://download.cloudstack.org/cks/setup-1.23.3.iso"
+  min_cpu               = 2
+  min_memory            = 2048
+}
+
+data "cloudstack_kubernetes_versions" "foo" {
+  minimum_semantic_version = cloudstack_kubernetes_version.foo.semantic_version
+  only_ready               = true
+}`
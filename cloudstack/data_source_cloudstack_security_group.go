@@ -0,0 +1,150 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceCloudstackSecurityGroup() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCloudstackSecurityGroupRead,
+
+		Schema: map[string]*schema.Schema{
+			"filter": dataSourceFiltersSchema(),
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			//Computed values
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"account": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"domain_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"tags": tagsSchema(),
+		},
+	}
+}
+
+func dataSourceCloudstackSecurityGroupRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	p := cs.SecurityGroup.NewListSecurityGroupsParams()
+	p.SetListall(true)
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return err
+	}
+
+	csSecurityGroups, err := cs.SecurityGroup.ListSecurityGroups(p)
+	if err != nil {
+		return fmt.Errorf("Failed to list security groups: %s", err)
+	}
+
+	filters := d.Get("filter")
+	var securityGroups []*cloudstack.SecurityGroup
+
+	for _, sg := range csSecurityGroups.SecurityGroups {
+		match, err := applySecurityGroupFilters(sg, filters.(*schema.Set))
+		if err != nil {
+			return err
+		}
+		if match {
+			securityGroups = append(securityGroups, sg)
+		}
+	}
+
+	if len(securityGroups) == 0 {
+		return fmt.Errorf("No security group is matching with the specified regex")
+	}
+	if len(securityGroups) > 1 {
+		return fmt.Errorf(
+			"%d security groups are matching with the specified regex, "+
+				"please add a filter on for example the account", len(securityGroups))
+	}
+	log.Printf("[DEBUG] Selected security group: %s\n", securityGroups[0].Name)
+
+	return securityGroupDescriptionAttributes(d, securityGroups[0])
+}
+
+func securityGroupDescriptionAttributes(d *schema.ResourceData, sg *cloudstack.SecurityGroup) error {
+	d.SetId(sg.Id)
+	d.Set("name", sg.Name)
+	d.Set("description", sg.Description)
+	d.Set("account", sg.Account)
+	d.Set("domain_id", sg.Domainid)
+	d.Set("tags", tagsToMap(sg.Tags))
+
+	setValueOrID(d, "project", sg.Project, sg.Projectid)
+
+	return nil
+}
+
+func applySecurityGroupFilters(sg *cloudstack.SecurityGroup, filters *schema.Set) (bool, error) {
+	var sgJSON map[string]interface{}
+	k, _ := json.Marshal(sg)
+	err := json.Unmarshal(k, &sgJSON)
+	if err != nil {
+		return false, err
+	}
+
+	for _, f := range filters.List() {
+		m := f.(map[string]interface{})
+		r, err := regexp.Compile(m["value"].(string))
+		if err != nil {
+			return false, fmt.Errorf("Invalid regex: %s", err)
+		}
+		updatedName := strings.ReplaceAll(m["name"].(string), "_", "")
+		sgField, ok := sgJSON[updatedName].(string)
+		if !ok {
+			return false, fmt.Errorf("Cannot filter security groups on %q", m["name"].(string))
+		}
+		if !r.MatchString(sgField) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
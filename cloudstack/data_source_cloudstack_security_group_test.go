@@ -0,0 +1,67 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSecurityGroupDataSource_basic(t *testing.T) {
+	resourceName := "cloudstack_security_group.foo"
+	datasourceName := "data.cloudstack_security_group.foo"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecurityGroupDataSourceConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(datasourceName, "id", resourceName, "id"),
+					resource.TestCheckResourceAttrPair(datasourceName, "description", resourceName, "description"),
+					resource.TestCheckResourceAttr(datasourceName, "tags.terraform-tag", "true"),
+				),
+			},
+		},
+	})
+}
+
+const testAccSecurityGroupDataSourceConfig_basic = `
+resource "cloudstack_security_group" "foo" {
+  name = "terraform-security-group-data"
+  description = "terraform-security-group-text"
+
+  tags = {
+    terraform-tag = "true"
+  }
+}
+
+data "cloudstack_security_group" "foo" {
+  filter {
+    name = "name"
+    value = "^terraform-security-group-data$"
+  }
+
+  depends_on = [
+    cloudstack_security_group.foo
+  ]
+}`
@@ -0,0 +1,70 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceCloudstackServiceDefinition() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCloudstackServiceDefinitionRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			//Computed values
+			"protocol": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"ports": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceCloudstackServiceDefinitionRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	def, err := getServiceDefinition(name)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(name)
+	d.Set("protocol", def.protocol)
+	d.Set("ports", def.ports)
+	d.Set("description", def.description)
+
+	return nil
+}
@@ -0,0 +1,132 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// kubeConfigFields maps the kubeconfig keys that are exported to the name of
+// the attribute they are exported as. Keys ending in "-data" are base64
+// encoded and are decoded to PEM before they are exported.
+var kubeConfigFields = map[string]string{
+	"server":                     "host",
+	"certificate-authority-data": "cluster_ca_certificate",
+	"client-certificate-data":    "client_certificate",
+	"client-key-data":            "client_key",
+}
+
+// kubeConfigSchema returns the schema of the attributes that are exported for
+// the kubeconfig of a Kubernetes cluster.
+func kubeConfigSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"raw_config": {
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		},
+	}
+
+	for _, attr := range kubeConfigFields {
+		s[attr] = &schema.Schema{
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		}
+	}
+
+	return s
+}
+
+// getKubernetesClusterConfig retrieves the kubeconfig of a Kubernetes cluster
+// and returns it as a map of exported attributes.
+func getKubernetesClusterConfig(cs *cloudstack.CloudStackClient, id string) (map[string]interface{}, error) {
+	p := cs.Kubernetes.NewGetKubernetesClusterConfigParams()
+	p.SetId(id)
+
+	r, err := cs.Kubernetes.GetKubernetesClusterConfig(p)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Error retrieving the config of Kubernetes Cluster %s: %s", id, err)
+	}
+
+	return parseKubeConfig(r.Configdata)
+}
+
+// parseKubeConfig extracts the API server address and the credentials of the
+// first cluster and user from a kubeconfig. The kubeconfig generated by
+// CloudStack always contains a single cluster and user, so a simple line based
+// scan is sufficient here.
+func parseKubeConfig(raw string) (map[string]interface{}, error) {
+	config := map[string]interface{}{
+		"raw_config": raw,
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimPrefix(strings.TrimSpace(parts[0]), "- ")
+		attr, ok := kubeConfigFields[key]
+		if !ok {
+			continue
+		}
+		if _, ok := config[attr]; ok {
+			continue
+		}
+
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		if strings.HasSuffix(key, "-data") {
+			pem, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("Error decoding %s of kubeconfig: %s", key, err)
+			}
+			value = string(pem)
+		}
+
+		config[attr] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading kubeconfig: %s", err)
+	}
+
+	if _, ok := config["host"]; !ok {
+		return nil, fmt.Errorf("Error reading kubeconfig: no cluster server found")
+	}
+
+	for _, attr := range kubeConfigFields {
+		if _, ok := config[attr]; !ok {
+			config[attr] = ""
+		}
+	}
+
+	return config, nil
+}
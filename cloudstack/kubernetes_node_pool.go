@@ -0,0 +1,371 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// The node types CKS supports per node pool.
+var kubernetesNodeRoles = []string{"CONTROL", "WORKER", "ETCD"}
+
+func kubernetesNodePoolSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: len(kubernetesNodeRoles),
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"role": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ForceNew:     true,
+					ValidateFunc: validation.StringInSlice(kubernetesNodeRoles, false),
+				},
+
+				"service_offering": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+
+				"template": {
+					Type:     schema.TypeString,
+					Optional: true,
+					ForceNew: true,
+				},
+
+				"affinity_group_id": {
+					Type:     schema.TypeString,
+					Optional: true,
+					ForceNew: true,
+				},
+
+				"size": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// kubernetesNodeParams makes the parameters of a custom request usable with
+// the helpers that set the project ID.
+type kubernetesNodeParams struct {
+	*cloudstack.CustomServiceParams
+}
+
+func (p kubernetesNodeParams) SetProjectid(v string) {
+	p.SetParam("projectid", v)
+}
+
+// kubernetesClusterNodes holds the node pool details of a cluster, which the
+// API client doesn't know about yet.
+type kubernetesClusterNodes struct {
+	Controlofferingid   string `json:"controlofferingid"`
+	Controlofferingname string `json:"controlofferingname"`
+	Workerofferingid    string `json:"workerofferingid"`
+	Workerofferingname  string `json:"workerofferingname"`
+	Etcdofferingid      string `json:"etcdofferingid"`
+	Etcdofferingname    string `json:"etcdofferingname"`
+	Etcdnodes           int64  `json:"etcdnodes"`
+}
+
+func (n *kubernetesClusterNodes) offering(role string) (string, string) {
+	switch role {
+	case "CONTROL":
+		return n.Controlofferingname, n.Controlofferingid
+	case "WORKER":
+		return n.Workerofferingname, n.Workerofferingid
+	case "ETCD":
+		return n.Etcdofferingname, n.Etcdofferingid
+	}
+	return "", ""
+}
+
+// createKubernetesClusterWithNodePools creates a cluster using the node
+// pools, and returns the ID of the new cluster. The API client doesn't
+// support node pools yet, so the request is made as a custom request.
+func createKubernetesClusterWithNodePools(
+	cs *cloudstack.CloudStackClient, d *schema.ResourceData, kubernetesVersionID, serviceOfferingID, zoneID string) (string, error) {
+	name := d.Get("name").(string)
+
+	p := kubernetesNodeParams{&cloudstack.CustomServiceParams{}}
+	p.SetParam("name", name)
+	p.SetParam("description", name)
+	p.SetParam("kubernetesversionid", kubernetesVersionID)
+	p.SetParam("serviceofferingid", serviceOfferingID)
+	p.SetParam("size", int64(d.Get("size").(int)))
+	p.SetParam("zoneid", zoneID)
+
+	// Set optional params
+	if description, ok := d.GetOk("description"); ok {
+		p.SetParam("description", description.(string))
+	}
+	if keypair, ok := d.GetOk("keypair"); ok {
+		p.SetParam("keypair", keypair.(string))
+	}
+	if networkID, ok := d.GetOk("network_id"); ok {
+		p.SetParam("networkid", networkID.(string))
+	}
+	if controlNodesSize, ok := d.GetOk("control_nodes_size"); ok {
+		p.SetParam("controlnodes", int64(controlNodesSize.(int)))
+	}
+	if noderootdisksize, ok := d.GetOk("noderootdisksize"); ok {
+		p.SetParam("noderootdisksize", int64(noderootdisksize.(int)))
+	}
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return "", err
+	}
+
+	if err := setKubernetesNodePoolParams(cs, p.CustomServiceParams, d.Get("node_pool").([]interface{}), zoneID); err != nil {
+		return "", err
+	}
+
+	var r struct {
+		Id    string `json:"id"`
+		JobID string `json:"jobid"`
+	}
+	custom, err := customService(cs)
+	if err != nil {
+		return "", err
+	}
+	if err := custom.CustomPostRequest("createKubernetesCluster", p.CustomServiceParams, &r); err != nil {
+		return "", err
+	}
+
+	if err := waitForKubernetesJob(cs, d, r.JobID, schema.TimeoutCreate); err != nil {
+		return r.Id, err
+	}
+
+	return r.Id, nil
+}
+
+// setKubernetesNodePoolParams sets the indexed parameters that configure the
+// service offering, template and affinity group per node role.
+func setKubernetesNodePoolParams(
+	cs *cloudstack.CloudStackClient, p *cloudstack.CustomServiceParams, pools []interface{}, zoneID string) error {
+	for i, pool := range pools {
+		pool := pool.(map[string]interface{})
+		role := pool["role"].(string)
+
+		offeringid, e := retrieveID(cs, "service_offering", pool["service_offering"].(string))
+		if e != nil {
+			return e.Error()
+		}
+		p.SetParam(fmt.Sprintf("nodeofferings[%d].node", i), role)
+		p.SetParam(fmt.Sprintf("nodeofferings[%d].offering", i), offeringid)
+
+		if template := pool["template"].(string); template != "" {
+			templateid, e := retrieveTemplateID(cs, zoneID, template)
+			if e != nil {
+				return e.Error()
+			}
+			p.SetParam(fmt.Sprintf("nodetemplates[%d].node", i), role)
+			p.SetParam(fmt.Sprintf("nodetemplates[%d].template", i), templateid)
+		}
+
+		if group := pool["affinity_group_id"].(string); group != "" {
+			p.SetParam(fmt.Sprintf("nodeaffinitygroups[%d].node", i), role)
+			p.SetParam(fmt.Sprintf("nodeaffinitygroups[%d].affinitygroup", i), group)
+		}
+
+		if role == "ETCD" {
+			p.SetParam("etcdnodes", int64(pool["size"].(int)))
+		}
+	}
+
+	return nil
+}
+
+// scaleKubernetesNodePools changes the service offering of all node pools
+// whose service offering changed.
+func scaleKubernetesNodePools(cs *cloudstack.CloudStackClient, d *schema.ResourceData) error {
+	o, n := d.GetChange("node_pool")
+
+	current := make(map[string]string)
+	for _, pool := range o.([]interface{}) {
+		pool := pool.(map[string]interface{})
+		current[pool["role"].(string)] = pool["service_offering"].(string)
+	}
+
+	p := &cloudstack.CustomServiceParams{}
+	p.SetParam("id", d.Id())
+
+	i := 0
+	for _, pool := range n.([]interface{}) {
+		pool := pool.(map[string]interface{})
+		role := pool["role"].(string)
+
+		if current[role] == pool["service_offering"].(string) {
+			continue
+		}
+
+		offeringid, e := retrieveID(cs, "service_offering", pool["service_offering"].(string))
+		if e != nil {
+			return e.Error()
+		}
+		p.SetParam(fmt.Sprintf("nodeofferings[%d].node", i), role)
+		p.SetParam(fmt.Sprintf("nodeofferings[%d].offering", i), offeringid)
+		i++
+	}
+
+	if i == 0 {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Scaling the node pools of Kubernetes Cluster %s", d.Id())
+	var r struct {
+		JobID string `json:"jobid"`
+	}
+	custom, err := customService(cs)
+	if err != nil {
+		return err
+	}
+	if err := custom.CustomPostRequest("scaleKubernetesCluster", p, &r); err != nil {
+		return err
+	}
+
+	return waitForKubernetesJob(cs, d, r.JobID, schema.TimeoutUpdate)
+}
+
+// customService returns the service used to make requests the API client
+// doesn't support yet.
+func customService(cs *cloudstack.CloudStackClient) (*cloudstack.CustomService, error) {
+	s, ok := cs.Custom.(*cloudstack.CustomService)
+	if !ok {
+		return nil, fmt.Errorf("The API client doesn't support custom requests")
+	}
+	return s, nil
+}
+
+func waitForKubernetesJob(cs *cloudstack.CloudStackClient, d *schema.ResourceData, jobid string, timeout string) error {
+	_, err := cs.GetAsyncJobResult(jobid, int64(d.Timeout(timeout)/time.Second))
+	return err
+}
+
+// setKubernetesNodePools reads back the service offering and size of the
+// configured node pools.
+func setKubernetesNodePools(cs *cloudstack.CloudStackClient, d *schema.ResourceData) error {
+	pools := d.Get("node_pool").([]interface{})
+	if len(pools) == 0 {
+		return nil
+	}
+
+	p := &cloudstack.CustomServiceParams{}
+	p.SetParam("id", d.Id())
+	if err := setProjectid(kubernetesNodeParams{p}, cs, d); err != nil {
+		return err
+	}
+
+	var r struct {
+		Count    int                       `json:"count"`
+		Clusters []*kubernetesClusterNodes `json:"kubernetescluster"`
+	}
+	custom, err := customService(cs)
+	if err != nil {
+		return err
+	}
+	if err := custom.CustomRequest("listKubernetesClusters", p, &r); err != nil {
+		return err
+	}
+
+	if r.Count == 0 {
+		return nil
+	}
+	nodes := r.Clusters[0]
+
+	for _, pool := range pools {
+		pool := pool.(map[string]interface{})
+		role := pool["role"].(string)
+
+		// Older versions of CKS don't return the offerings per node type
+		name, id := nodes.offering(role)
+		if id != "" {
+			if cloudstack.IsID(pool["service_offering"].(string)) {
+				pool["service_offering"] = id
+			} else {
+				pool["service_offering"] = name
+			}
+		}
+
+		if role == "ETCD" && nodes.Etcdnodes > 0 {
+			pool["size"] = int(nodes.Etcdnodes)
+		}
+	}
+
+	return d.Set("node_pool", pools)
+}
+
+// verifyKubernetesNodePools validates the configured node pools.
+func verifyKubernetesNodePools(pools []interface{}) error {
+	roles := make(map[string]bool)
+	for _, pool := range pools {
+		pool := pool.(map[string]interface{})
+		role := pool["role"].(string)
+
+		if roles[role] {
+			return fmt.Errorf("Only one node_pool can be configured for role %s", role)
+		}
+		roles[role] = true
+
+		size := pool["size"].(int)
+		if role == "ETCD" && size < 1 {
+			return fmt.Errorf("The size of the ETCD node_pool must be at least 1")
+		}
+		if role != "ETCD" && size != 0 {
+			return fmt.Errorf(
+				"The size of the %s node_pool is set by the size or control_nodes_size of the cluster", role)
+		}
+	}
+
+	return nil
+}
+
+// verifyKubernetesEtcdResize rejects changing the number of etcd nodes of an
+// existing cluster, as CKS can't scale the etcd nodes.
+func verifyKubernetesEtcdResize(o, n []interface{}) error {
+	from, to := kubernetesEtcdNodes(o), kubernetesEtcdNodes(n)
+	if from > 0 && to > 0 && from != to {
+		return fmt.Errorf(
+			"The size of the ETCD node_pool can't be changed from %d to %d, "+
+				"as the etcd nodes of an existing cluster can't be scaled", from, to)
+	}
+	return nil
+}
+
+func kubernetesEtcdNodes(pools []interface{}) int {
+	for _, pool := range pools {
+		pool := pool.(map[string]interface{})
+		if pool["role"].(string) == "ETCD" {
+			return pool["size"].(int)
+		}
+	}
+	return 0
+}
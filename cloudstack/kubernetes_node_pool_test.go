@@ -0,0 +1,153 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+)
+
+func testNodePool(role, offering, template, group string, size int) map[string]interface{} {
+	return map[string]interface{}{
+		"role":              role,
+		"service_offering":  offering,
+		"template":          template,
+		"affinity_group_id": group,
+		"size":              size,
+	}
+}
+
+func TestVerifyKubernetesNodePools(t *testing.T) {
+	offering := "d8a5a4e1-0c4b-4c8e-9a5c-3b1f4f1b2c3d"
+
+	cases := []struct {
+		Pools []interface{}
+		Error bool
+	}{
+		{nil, false},
+		{[]interface{}{
+			testNodePool("CONTROL", offering, "", "", 0),
+			testNodePool("WORKER", offering, "", "", 0),
+			testNodePool("ETCD", offering, "", "", 3),
+		}, false},
+		{[]interface{}{
+			testNodePool("WORKER", offering, "", "", 0),
+			testNodePool("WORKER", offering, "", "", 0),
+		}, true},
+		{[]interface{}{testNodePool("ETCD", offering, "", "", 0)}, true},
+		{[]interface{}{testNodePool("WORKER", offering, "", "", 2)}, true},
+		{[]interface{}{testNodePool("CONTROL", offering, "", "", 1)}, true},
+	}
+
+	for i, c := range cases {
+		err := verifyKubernetesNodePools(c.Pools)
+		if c.Error && err == nil {
+			t.Fatalf("case %d: expected an error", i)
+		}
+		if !c.Error && err != nil {
+			t.Fatalf("case %d: unexpected error: %s", i, err)
+		}
+	}
+}
+
+func TestVerifyKubernetesEtcdResize(t *testing.T) {
+	offering := "d8a5a4e1-0c4b-4c8e-9a5c-3b1f4f1b2c3d"
+	pools := func(size int) []interface{} {
+		return []interface{}{
+			testNodePool("WORKER", offering, "", "", 0),
+			testNodePool("ETCD", offering, "", "", size),
+		}
+	}
+
+	cases := []struct {
+		Old   []interface{}
+		New   []interface{}
+		Error bool
+	}{
+		{pools(3), pools(3), false},
+		{pools(3), pools(5), true},
+		{pools(3), pools(1), true},
+		{nil, pools(3), false},
+		{pools(3), nil, false},
+	}
+
+	for i, c := range cases {
+		err := verifyKubernetesEtcdResize(c.Old, c.New)
+		if c.Error && err == nil {
+			t.Fatalf("case %d: expected an error", i)
+		}
+		if !c.Error && err != nil {
+			t.Fatalf("case %d: unexpected error: %s", i, err)
+		}
+	}
+}
+
+func TestSetKubernetesNodePoolParams(t *testing.T) {
+	control := "0b6a4e1e-6f3c-4a43-9a0e-1c2d3e4f5a6b"
+	worker := "1c7b5f2f-7a4d-4b54-8b1f-2d3e4f5a6b7c"
+	etcd := "2d8c6a3a-8b5e-4c65-9c2a-3e4f5a6b7c8d"
+	template := "3e9d7b4b-9c6f-4d76-8d3b-4f5a6b7c8d9e"
+	group := "4fae8c5c-ad7a-4e87-9e4c-5a6b7c8d9eaf"
+
+	// IDs are used as is, so no API requests are made
+	cs := cloudstack.NewClient("http://localhost", "key", "secret", false)
+
+	pools := []interface{}{
+		testNodePool("CONTROL", control, "", group, 0),
+		testNodePool("WORKER", worker, template, "", 0),
+		testNodePool("ETCD", etcd, "", "", 3),
+	}
+
+	p := &cloudstack.CustomServiceParams{}
+	if err := setKubernetesNodePoolParams(cs, p, pools, "zone"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"nodeofferings[0].node":               "CONTROL",
+		"nodeofferings[0].offering":           control,
+		"nodeofferings[1].node":               "WORKER",
+		"nodeofferings[1].offering":           worker,
+		"nodeofferings[2].node":               "ETCD",
+		"nodeofferings[2].offering":           etcd,
+		"nodetemplates[1].node":               "WORKER",
+		"nodetemplates[1].template":           template,
+		"nodeaffinitygroups[0].node":          "CONTROL",
+		"nodeaffinitygroups[0].affinitygroup": group,
+		"etcdnodes":                           int64(3),
+	}
+	for k, v := range expected {
+		if got, ok := p.GetParam(k); !ok || got != v {
+			t.Fatalf("expected %s to be %v, got %v", k, v, got)
+		}
+	}
+
+	for _, k := range []string{
+		"nodetemplates[0].node",
+		"nodetemplates[2].node",
+		"nodeaffinitygroups[1].node",
+		"nodeaffinitygroups[2].node",
+	} {
+		if _, ok := p.GetParam(k); ok {
+			t.Fatalf("expected %s not to be set", k)
+		}
+	}
+}
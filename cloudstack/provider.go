@@ -24,6 +24,7 @@ import (
 
 	"github.com/go-ini/ini"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func Provider() *schema.Provider {
@@ -75,26 +76,62 @@ func Provider() *schema.Provider {
 				Required:    true,
 				DefaultFunc: schema.EnvDefaultFunc("CLOUDSTACK_TIMEOUT", 900),
 			},
+
+			"service": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"protocol": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"tcp", "udp"}, false),
+						},
+
+						"ports": {
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"cloudstack_domain":           dataSourceCloudstackDomain(),
-			"cloudstack_instance":         dataSourceCloudstackInstance(),
-			"cloudstack_ipaddress":        dataSourceCloudstackIPAddress(),
-			"cloudstack_limits":           dataSourceCloudStackLimits(),
-			"cloudstack_network_offering": dataSourceCloudstackNetworkOffering(),
-			"cloudstack_physicalnetwork":  dataSourceCloudStackPhysicalNetwork(),
-			"cloudstack_pod":              dataSourceCloudstackPod(),
-			"cloudstack_project":          dataSourceCloudstackProject(),
-			"cloudstack_role":             dataSourceCloudstackRole(),
-			"cloudstack_service_offering": dataSourceCloudstackServiceOffering(),
-			"cloudstack_ssh_keypair":      dataSourceCloudstackSSHKeyPair(),
-			"cloudstack_template":         dataSourceCloudstackTemplate(),
-			"cloudstack_user":             dataSourceCloudstackUser(),
-			"cloudstack_volume":           dataSourceCloudstackVolume(),
-			"cloudstack_vpc":              dataSourceCloudstackVPC(),
-			"cloudstack_vpn_connection":   dataSourceCloudstackVPNConnection(),
-			"cloudstack_zone":             dataSourceCloudStackZone(),
+			"cloudstack_domain":                    dataSourceCloudstackDomain(),
+			"cloudstack_instance":                  dataSourceCloudstackInstance(),
+			"cloudstack_ipaddress":                 dataSourceCloudstackIPAddress(),
+			"cloudstack_iso":                       dataSourceCloudstackISO(),
+			"cloudstack_kubernetes_cluster_config": dataSourceCloudstackKubernetesClusterConfig(),
+			"cloudstack_kubernetes_versions":       dataSourceCloudstackKubernetesVersions(),
+			"cloudstack_limits":                    dataSourceCloudStackLimits(),
+			"cloudstack_network_offering":          dataSourceCloudstackNetworkOffering(),
+			"cloudstack_physicalnetwork":           dataSourceCloudStackPhysicalNetwork(),
+			"cloudstack_pod":                       dataSourceCloudstackPod(),
+			"cloudstack_project":                   dataSourceCloudstackProject(),
+			"cloudstack_role":                      dataSourceCloudstackRole(),
+			"cloudstack_service_definition":        dataSourceCloudstackServiceDefinition(),
+			"cloudstack_service_offering":          dataSourceCloudstackServiceOffering(),
+			"cloudstack_security_group":            dataSourceCloudstackSecurityGroup(),
+			"cloudstack_ssh_keypair":               dataSourceCloudstackSSHKeyPair(),
+			"cloudstack_template":                  dataSourceCloudstackTemplate(),
+			"cloudstack_user":                      dataSourceCloudstackUser(),
+			"cloudstack_volume":                    dataSourceCloudstackVolume(),
+			"cloudstack_vpc":                       dataSourceCloudstackVPC(),
+			"cloudstack_vpn_connection":            dataSourceCloudstackVPNConnection(),
+			"cloudstack_zone":                      dataSourceCloudStackZone(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -111,6 +148,8 @@ func Provider() *schema.Provider {
 			"cloudstack_host":                     resourceCloudStackHost(),
 			"cloudstack_instance":                 resourceCloudStackInstance(),
 			"cloudstack_ipaddress":                resourceCloudStackIPAddress(),
+			"cloudstack_iso":                      resourceCloudStackISO(),
+			"cloudstack_ipv6_firewall_rule":       resourceCloudStackIPv6FirewallRule(),
 			"cloudstack_kubernetes_cluster":       resourceCloudStackKubernetesCluster(),
 			"cloudstack_kubernetes_version":       resourceCloudStackKubernetesVersion(),
 			"cloudstack_limits":                   resourceCloudStackLimits(),
@@ -125,6 +164,7 @@ func Provider() *schema.Provider {
 			"cloudstack_port_forward":             resourceCloudStackPortForward(),
 			"cloudstack_private_gateway":          resourceCloudStackPrivateGateway(),
 			"cloudstack_project":                  resourceCloudStackProject(),
+			"cloudstack_remote_access_vpn":        resourceCloudStackRemoteAccessVPN(),
 			"cloudstack_role":                     resourceCloudStackRole(),
 			"cloudstack_secondary_ipaddress":      resourceCloudStackSecondaryIPAddress(),
 			"cloudstack_security_group_rule":      resourceCloudStackSecurityGroupRule(),
@@ -135,13 +175,17 @@ func Provider() *schema.Provider {
 			"cloudstack_static_route":             resourceCloudStackStaticRoute(),
 			"cloudstack_tags":                     resourceCloudStackTags(),
 			"cloudstack_template":                 resourceCloudStackTemplate(),
+			"cloudstack_template_copy":            resourceCloudStackTemplateCopy(),
+			"cloudstack_template_permissions":     resourceCloudStackTemplatePermissions(),
 			"cloudstack_traffic_type":             resourceCloudStackTrafficType(),
 			"cloudstack_user":                     resourceCloudStackUser(),
 			"cloudstack_volume":                   resourceCloudStackVolume(),
+			"cloudstack_vlan_ip_range":            resourceCloudStackVlanIpRange(),
 			"cloudstack_vpc":                      resourceCloudStackVPC(),
 			"cloudstack_vpn_connection":           resourceCloudStackVPNConnection(),
 			"cloudstack_vpn_customer_gateway":     resourceCloudStackVPNCustomerGateway(),
 			"cloudstack_vpn_gateway":              resourceCloudStackVPNGateway(),
+			"cloudstack_vpn_user":                 resourceCloudStackVPNUser(),
 			"cloudstack_zone":                     resourceCloudStackZone(),
 		},
 
@@ -186,6 +230,10 @@ func providerConfigure(d *schema.ResourceData) (any, error) {
 		secretKey = section.Key("secretkey").String()
 	}
 
+	if err := setCustomServiceDefinitions(d.Get("service").([]interface{})); err != nil {
+		return nil, err
+	}
+
 	cfg := Config{
 		APIURL:      apiURL.(string),
 		APIKey:      apiKey.(string),
@@ -40,6 +40,12 @@ var testAccMuxProvider map[string]func() (tfprotov6.ProviderServer, error)
 
 var cloudStackTemplateURL = os.Getenv("CLOUDSTACK_TEMPLATE_URL")
 
+var cloudStackSecondZone = os.Getenv("CLOUDSTACK_SECOND_ZONE")
+
+var cloudStackTemplateFile = os.Getenv("CLOUDSTACK_TEMPLATE_FILE")
+
+var cloudStackISOURL = os.Getenv("CLOUDSTACK_ISO_URL")
+
 func init() {
 	testAccProvider = Provider()
 	testAccProviders = map[string]*schema.Provider{
@@ -87,6 +93,22 @@ func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
 
+func TestMuxServerSchema(t *testing.T) {
+	server, err := testAccMuxProvider["cloudstack"]()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The mux server rejects provider schemas that differ between the providers
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, diag := range resp.Diagnostics {
+		t.Fatalf("%s: %s", diag.Summary, diag.Detail)
+	}
+}
+
 func TestMuxServer(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccMuxProvider,
@@ -25,6 +25,7 @@ type CloudstackProviderModel struct {
 	Profile     types.String `tfsdk:"profile"`
 	HttpGetOnly types.Bool   `tfsdk:"http_get_only"`
 	Timeout     types.Int64  `tfsdk:"timeout"`
+	Service     types.List   `tfsdk:"service"`
 }
 
 var _ provider.Provider = (*CloudstackProvider)(nil)
@@ -64,6 +65,28 @@ func (p *CloudstackProvider) Schema(ctx context.Context, req provider.SchemaRequ
 				Optional: true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			// The services are registered by the SDK provider
+			"service": schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required: true,
+						},
+						"protocol": schema.StringAttribute{
+							Required: true,
+						},
+						"ports": schema.SetAttribute{
+							ElementType: types.StringType,
+							Required:    true,
+						},
+						"description": schema.StringAttribute{
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -20,7 +20,10 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,6 +41,12 @@ func resourceCloudStackEgressFirewall() *schema.Resource {
 		Update: resourceCloudStackEgressFirewallUpdate,
 		Delete: resourceCloudStackEgressFirewallDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceCloudStackEgressFirewallImport,
+		},
+
+		CustomizeDiff: resourceCloudStackEgressFirewallCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"network_id": {
 				Type:     schema.TypeString,
@@ -58,6 +67,11 @@ func resourceCloudStackEgressFirewall() *schema.Resource {
 				Default:  false,
 			},
 
+			"default_policy": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
 			"rule": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -195,7 +209,7 @@ func createEgressFirewallRule(d *schema.ResourceData, meta interface{}, rule map
 	}
 
 	// If the protocol is ICMP set the needed ICMP parameters
-	if rule["protocol"].(string) == "icmp" {
+	if _, ok := uuids["icmp"]; !ok && rule["protocol"].(string) == "icmp" {
 		p.SetIcmptype(rule["icmp_type"].(int))
 		p.SetIcmpcode(rule["icmp_code"].(int))
 
@@ -253,7 +267,7 @@ func createEgressFirewallRule(d *schema.ResourceData, meta interface{}, rule map
 		}
 	}
 
-	if strings.ToLower(rule["protocol"].(string)) == "all" {
+	if _, ok := uuids["all"]; !ok && strings.ToLower(rule["protocol"].(string)) == "all" {
 		r, err := cs.Firewall.CreateEgressFirewallRule(p)
 		if err != nil {
 			return err
@@ -267,6 +281,24 @@ func createEgressFirewallRule(d *schema.ResourceData, meta interface{}, rule map
 func resourceCloudStackEgressFirewallRead(d *schema.ResourceData, meta interface{}) error {
 	cs := meta.(*cloudstack.CloudStackClient)
 
+	// Get the network to read the default egress policy
+	n, count, err := cs.Network.GetNetworkByID(
+		d.Id(),
+		cloudstack.WithProject(d.Get("project").(string)),
+	)
+	if err != nil {
+		if count == 0 {
+			log.Printf(
+				"[DEBUG] Network %s does no longer exist", d.Id())
+			d.SetId("")
+			return nil
+		}
+
+		return err
+	}
+
+	d.Set("default_policy", egressDefaultPolicy(n))
+
 	// Get all the rules from the running environment
 	p := cs.Firewall.NewListEgressFirewallRulesParams()
 	p.SetNetworkid(d.Id())
@@ -450,9 +482,13 @@ func resourceCloudStackEgressFirewallUpdate(d *schema.ResourceData, meta interfa
 		// set to make sure we end up in a consistent state
 		rules := o.(*schema.Set).Intersection(n.(*schema.Set))
 
-		// First loop through all the old rules and delete them
-		if ors.Len() > 0 {
-			err := deleteEgressFirewallRules(d, meta, rules, ors)
+		// Pair changed rules that only differ in their ports, so only the
+		// ports that are actually added or removed are touched
+		pairRules(ors, nrs, firewallRuleIdentity, ruleUnits)
+
+		// First loop through all the new rules and create (before destroy) them
+		if nrs.Len() > 0 {
+			err := createEgressFirewallRules(d, meta, rules, nrs)
 
 			// We need to update this first to preserve the correct state
 			d.Set("rule", rules)
@@ -462,9 +498,9 @@ func resourceCloudStackEgressFirewallUpdate(d *schema.ResourceData, meta interfa
 			}
 		}
 
-		// Then loop through all the new rules and create them
-		if nrs.Len() > 0 {
-			err := createEgressFirewallRules(d, meta, rules, nrs)
+		// Then loop through all the old rules and delete them
+		if ors.Len() > 0 {
+			err := deleteEgressFirewallRules(d, meta, rules, ors)
 
 			// We need to update this first to preserve the correct state
 			d.Set("rule", rules)
@@ -569,6 +605,196 @@ func deleteEgressFirewallRule(d *schema.ResourceData, meta interface{}, rule map
 	return nil
 }
 
+func resourceCloudStackEgressFirewallCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := verifyRuleSet(d, "cloudstack_egress_firewall", "rule", "egress"); err != nil {
+		return err
+	}
+
+	if !d.NewValueKnown("network_id") || !d.NewValueKnown("rule") {
+		return nil
+	}
+
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	n, count, err := cs.Network.GetNetworkByID(
+		d.Get("network_id").(string),
+		cloudstack.WithProject(d.Get("project").(string)),
+	)
+	if err != nil {
+		if count == 0 {
+			// The network will be (re)created, so there is nothing to compare with
+			return nil
+		}
+		return err
+	}
+
+	entries, err := parseRuleSetEntries(d, "rule", "egress")
+	if err != nil {
+		return err
+	}
+
+	warnEgressDefaultPolicy(n, entries, d.Get("managed").(bool))
+
+	return nil
+}
+
+// egressDefaultPolicy returns the default egress policy of a network as
+// configured by its network offering.
+func egressDefaultPolicy(n *cloudstack.Network) string {
+	if n.Egressdefaultpolicy {
+		return "allow"
+	}
+	return "deny"
+}
+
+// warnEgressDefaultPolicy warns about rules that do not combine well with the
+// default egress policy of the network. When the default policy is allow, the
+// egress rules block the matching traffic instead of allowing it, so rules
+// that match all traffic reverse the default policy of the network. Blocking
+// or allowing all egress traffic can be intended, so these are only logged.
+func warnEgressDefaultPolicy(n *cloudstack.Network, entries []*ruleEntry, managed bool) {
+	policy := egressDefaultPolicy(n)
+
+	if len(entries) == 0 {
+		if managed {
+			verdict := "denied"
+			if policy == "allow" {
+				verdict = "allowed"
+			}
+			log.Printf(
+				"[WARN] cloudstack_egress_firewall: no rules are configured for network %s, "+
+					"so all egress traffic is %s by its default policy", n.Name, verdict)
+		}
+		return
+	}
+
+	if policy == "allow" {
+		log.Printf(
+			"[WARN] cloudstack_egress_firewall: the default egress policy of network %s is allow, "+
+				"so the configured rules block the matching traffic instead of allowing it", n.Name)
+	}
+
+	var networks []*net.IPNet
+	for _, cidr := range []string{n.Cidr, n.Ip6cidr} {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			networks = append(networks, network)
+		}
+	}
+
+	for _, e := range entries {
+		if e.protocol != "all" || e.network == nil || !coversNetwork(e.network, networks) {
+			continue
+		}
+
+		if policy == "allow" {
+			log.Printf(
+				"[WARN] cloudstack_egress_firewall: rule %s blocks all egress traffic, "+
+					"reversing the default allow policy of network %s", e, n.Name)
+		} else {
+			log.Printf(
+				"[WARN] cloudstack_egress_firewall: rule %s allows all egress traffic, "+
+					"making the default deny policy of network %s redundant", e, n.Name)
+		}
+	}
+}
+
+// coversNetwork returns true if cidr contains one of the given networks.
+func coversNetwork(cidr *net.IPNet, networks []*net.IPNet) bool {
+	ones, _ := cidr.Mask.Size()
+	if ones == 0 {
+		return true
+	}
+
+	for _, network := range networks {
+		size, bits := network.Mask.Size()
+		if _, cbits := cidr.Mask.Size(); cbits == bits && ones <= size && cidr.Contains(network.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func resourceCloudStackEgressFirewallImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if _, err := importStatePassthrough(d, meta); err != nil {
+		return nil, err
+	}
+	d.Set("network_id", d.Id())
+
+	// Get all the rules from the running environment
+	p := cs.Firewall.NewListEgressFirewallRulesParams()
+	p.SetNetworkid(d.Id())
+	p.SetListall(true)
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return nil, err
+	}
+
+	l, err := cs.Firewall.ListEgressFirewallRules(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an empty schema.Set to hold all rules
+	rules := resourceCloudStackEgressFirewall().Schema["rule"].ZeroValue().(*schema.Set)
+
+	// Group all port based rules by their protocol and CIDR list
+	grouped := make(map[string]map[string]interface{})
+
+	for _, r := range l.EgressFirewallRules {
+		// Create a set with all CIDR's
+		cidrs := &schema.Set{F: schema.HashString}
+		for _, cidr := range strings.Split(r.Cidrlist, ",") {
+			cidrs.Add(cidr)
+		}
+
+		switch strings.ToLower(r.Protocol) {
+		case "icmp":
+			rules.Add(map[string]interface{}{
+				"cidr_list": cidrs,
+				"protocol":  r.Protocol,
+				"icmp_type": r.Icmptype,
+				"icmp_code": r.Icmpcode,
+				"uuids":     map[string]interface{}{"icmp": r.Id},
+			})
+		case "all":
+			rules.Add(map[string]interface{}{
+				"cidr_list": cidrs,
+				"protocol":  r.Protocol,
+				"uuids":     map[string]interface{}{"all": r.Id},
+			})
+		default:
+			key := r.Protocol + "|" + sortedCIDRs(r.Cidrlist)
+
+			rule, ok := grouped[key]
+			if !ok {
+				rule = map[string]interface{}{
+					"cidr_list": cidrs,
+					"protocol":  r.Protocol,
+					"ports":     &schema.Set{F: schema.HashString},
+					"uuids":     make(map[string]interface{}),
+				}
+				grouped[key] = rule
+			}
+
+			port := rulePort(r.Startport, r.Endport)
+			rule["ports"].(*schema.Set).Add(port)
+			rule["uuids"].(map[string]interface{})[port] = r.Id
+		}
+	}
+
+	for _, rule := range grouped {
+		rules.Add(rule)
+	}
+
+	d.Set("rule", rules)
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func verifyEgressFirewallParams(d *schema.ResourceData) error {
 	managed := d.Get("managed").(bool)
 	_, rules := d.GetOk("rule")
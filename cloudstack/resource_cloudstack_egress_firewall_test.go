@@ -47,12 +47,34 @@ func TestAccCloudStackEgressFirewall_basic(t *testing.T) {
 						"cloudstack_egress_firewall.foo", "rule.0.protocol", "tcp"),
 					resource.TestCheckResourceAttr(
 						"cloudstack_egress_firewall.foo", "rule.0.ports.0", "8080"),
+					resource.TestCheckResourceAttrSet(
+						"cloudstack_egress_firewall.foo", "default_policy"),
 				),
 			},
 		},
 	})
 }
 
+func TestAccCloudStackEgressFirewall_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackEgressFirewallDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackEgressFirewall_basic,
+			},
+
+			{
+				ResourceName:            "cloudstack_egress_firewall.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"managed", "parallelism"},
+			},
+		},
+	})
+}
+
 func TestAccCloudStackEgressFirewall_update(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -20,6 +20,7 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -38,6 +39,12 @@ func resourceCloudStackFirewall() *schema.Resource {
 		Update: resourceCloudStackFirewallUpdate,
 		Delete: resourceCloudStackFirewallDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceCloudStackFirewallImport,
+		},
+
+		CustomizeDiff: resourceCloudStackFirewallCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"ip_address_id": {
 				Type:     schema.TypeString,
@@ -70,9 +77,14 @@ func resourceCloudStackFirewall() *schema.Resource {
 							Set:      schema.HashString,
 						},
 
+						"service": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
 						"protocol": {
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
 						},
 
 						"icmp_type": {
@@ -152,12 +164,15 @@ func createFirewallRules(d *schema.ResourceData, meta interface{}, rules *schema
 			defer wg.Done()
 			sem <- struct{}{}
 
+			// Expand the referenced service, if any
+			rule = withRuleService(rule)
+
 			// Create a single rule
 			err := createFirewallRule(d, meta, rule)
 
 			// If we have at least one UUID, we need to save the rule
 			if len(rule["uuids"].(map[string]interface{})) > 0 {
-				rules.Add(rule)
+				rules.Add(collapseRuleService(rule))
 			}
 
 			if err != nil {
@@ -193,7 +208,7 @@ func createFirewallRule(d *schema.ResourceData, meta interface{}, rule map[strin
 	p.SetCidrlist(cidrList)
 
 	// If the protocol is ICMP set the needed ICMP parameters
-	if rule["protocol"].(string) == "icmp" {
+	if _, ok := uuids["icmp"]; !ok && rule["protocol"].(string) == "icmp" {
 		p.SetIcmptype(rule["icmp_type"].(int))
 		p.SetIcmpcode(rule["icmp_code"].(int))
 
@@ -285,7 +300,8 @@ func resourceCloudStackFirewallRead(d *schema.ResourceData, meta interface{}) er
 	// Read all rules that are configured
 	if rs := d.Get("rule").(*schema.Set); rs.Len() > 0 {
 		for _, rule := range rs.List() {
-			rule := rule.(map[string]interface{})
+			// Expand the referenced service, if any, so its ports can be read
+			rule := withRuleService(rule.(map[string]interface{}))
 			uuids := rule["uuids"].(map[string]interface{})
 
 			if rule["protocol"].(string) == "icmp" {
@@ -315,7 +331,7 @@ func resourceCloudStackFirewallRead(d *schema.ResourceData, meta interface{}) er
 				rule["icmp_type"] = r.Icmptype
 				rule["icmp_code"] = r.Icmpcode
 				rule["cidr_list"] = cidrs
-				rules.Add(rule)
+				rules.Add(collapseRuleService(rule))
 			}
 
 			// If protocol is not ICMP, loop through all ports
@@ -357,7 +373,7 @@ func resourceCloudStackFirewallRead(d *schema.ResourceData, meta interface{}) er
 					// If there is at least one port found, add this rule to the rules set
 					if ports.Len() > 0 {
 						rule["ports"] = ports
-						rules.Add(rule)
+						rules.Add(collapseRuleService(rule))
 					}
 				}
 			}
@@ -412,9 +428,13 @@ func resourceCloudStackFirewallUpdate(d *schema.ResourceData, meta interface{})
 		// set to make sure we end up in a consistent state
 		rules := o.(*schema.Set).Intersection(n.(*schema.Set))
 
-		// First loop through all the old rules and delete them
-		if ors.Len() > 0 {
-			err := deleteFirewallRules(d, meta, rules, ors)
+		// Pair changed rules that only differ in their ports, so only the
+		// ports that are actually added or removed are touched
+		pairRules(ors, nrs, firewallRuleIdentity, ruleUnits)
+
+		// First loop through all the new rules and create (before destroy) them
+		if nrs.Len() > 0 {
+			err := createFirewallRules(d, meta, rules, nrs)
 
 			// We need to update this first to preserve the correct state
 			d.Set("rule", rules)
@@ -424,9 +444,9 @@ func resourceCloudStackFirewallUpdate(d *schema.ResourceData, meta interface{})
 			}
 		}
 
-		// Then loop through all the new rules and create them
-		if nrs.Len() > 0 {
-			err := createFirewallRules(d, meta, rules, nrs)
+		// Then loop through all the old rules and delete them
+		if ors.Len() > 0 {
+			err := deleteFirewallRules(d, meta, rules, ors)
 
 			// We need to update this first to preserve the correct state
 			d.Set("rule", rules)
@@ -531,6 +551,102 @@ func deleteFirewallRule(d *schema.ResourceData, meta interface{}, rule map[strin
 	return nil
 }
 
+func resourceCloudStackFirewallCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	return verifyRuleSet(d, "cloudstack_firewall", "rule", "ingress")
+}
+
+func resourceCloudStackFirewallImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if _, err := importStatePassthrough(d, meta); err != nil {
+		return nil, err
+	}
+	d.Set("ip_address_id", d.Id())
+
+	// Get all the rules from the running environment
+	p := cs.Firewall.NewListFirewallRulesParams()
+	p.SetIpaddressid(d.Id())
+	p.SetListall(true)
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return nil, err
+	}
+
+	l, err := cs.Firewall.ListFirewallRules(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an empty schema.Set to hold all rules
+	rules := resourceCloudStackFirewall().Schema["rule"].ZeroValue().(*schema.Set)
+
+	// Group all port based rules by their protocol and CIDR list
+	grouped := make(map[string]map[string]interface{})
+
+	for _, r := range l.FirewallRules {
+		// Create a set with all CIDR's
+		cidrs := &schema.Set{F: schema.HashString}
+		for _, cidr := range strings.Split(r.Cidrlist, ",") {
+			cidrs.Add(cidr)
+		}
+
+		if r.Protocol == "icmp" {
+			rules.Add(map[string]interface{}{
+				"cidr_list": cidrs,
+				"protocol":  r.Protocol,
+				"icmp_type": r.Icmptype,
+				"icmp_code": r.Icmpcode,
+				"uuids":     map[string]interface{}{"icmp": r.Id},
+			})
+			continue
+		}
+
+		key := r.Protocol + "|" + sortedCIDRs(r.Cidrlist)
+
+		rule, ok := grouped[key]
+		if !ok {
+			rule = map[string]interface{}{
+				"cidr_list": cidrs,
+				"protocol":  r.Protocol,
+				"ports":     &schema.Set{F: schema.HashString},
+				"uuids":     make(map[string]interface{}),
+			}
+			grouped[key] = rule
+		}
+
+		port := rulePort(r.Startport, r.Endport)
+		rule["ports"].(*schema.Set).Add(port)
+		rule["uuids"].(map[string]interface{})[port] = r.Id
+	}
+
+	for _, rule := range grouped {
+		rules.Add(rule)
+	}
+
+	d.Set("rule", rules)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// firewallRuleIdentity returns a key identifying a rule by everything but its
+// ports, as the CIDR list is part of every firewall rule created for a port.
+func firewallRuleIdentity(rule map[string]interface{}) string {
+	rule = withRuleService(rule)
+
+	var cidrs []string
+	for _, cidr := range rule["cidr_list"].(*schema.Set).List() {
+		cidrs = append(cidrs, cidr.(string))
+	}
+
+	return fmt.Sprintf("%s|%d|%d|%s",
+		rule["protocol"].(string),
+		rule["icmp_type"].(int),
+		rule["icmp_code"].(int),
+		sortedCIDRs(strings.Join(cidrs, ",")),
+	)
+}
+
 func verifyFirewallParams(d *schema.ResourceData) error {
 	managed := d.Get("managed").(bool)
 	_, rules := d.GetOk("rule")
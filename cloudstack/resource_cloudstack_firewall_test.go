@@ -61,6 +61,26 @@ func TestAccCloudStackFirewall_basic(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackFirewall_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackFirewallDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackFirewall_basic,
+			},
+
+			{
+				ResourceName:            "cloudstack_firewall.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"managed", "parallelism"},
+			},
+		},
+	})
+}
+
 func TestAccCloudStackFirewall_update(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -126,6 +146,30 @@ func TestAccCloudStackFirewall_update(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackFirewall_service(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackFirewallDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackFirewall_service,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackFirewallRulesExist("cloudstack_firewall.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_firewall.foo", "rule.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"cloudstack_firewall.foo", "rule.*", map[string]string{
+							"service": "web",
+							"ports.#": "0",
+							"uuids.%": "2",
+						}),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckCloudStackFirewallRulesExist(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -242,3 +286,22 @@ resource "cloudstack_firewall" "foo" {
     ports = ["80", "443"]
   }
 }`
+
+const testAccCloudStackFirewall_service = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  source_nat_ip = true
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_firewall" "foo" {
+  ip_address_id = cloudstack_network.foo.source_nat_ip_id
+
+  rule {
+    cidr_list = ["10.0.0.0/24"]
+    service = "web"
+  }
+}`
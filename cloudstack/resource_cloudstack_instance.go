@@ -20,6 +20,7 @@
 package cloudstack
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/hex"
@@ -37,6 +38,9 @@ func resourceCloudStackInstance() *schema.Resource {
 		Read:   resourceCloudStackInstanceRead,
 		Update: resourceCloudStackInstanceUpdate,
 		Delete: resourceCloudStackInstanceDelete,
+
+		CustomizeDiff: resourceCloudStackInstanceCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: resourceCloudStackInstanceImport,
 		},
@@ -73,9 +77,33 @@ func resourceCloudStackInstance() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"ip6_address": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
 			"template": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"iso": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"disk_offering": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"hypervisor": {
+				Type:     schema.TypeString,
+				Optional: true,
 				ForceNew: true,
 			},
 
@@ -111,7 +139,6 @@ func resourceCloudStackInstance() *schema.Resource {
 			"security_group_ids": {
 				Type:          schema.TypeSet,
 				Optional:      true,
-				ForceNew:      true,
 				Elem:          &schema.Schema{Type: schema.TypeString},
 				Set:           schema.HashString,
 				ConflictsWith: []string{"security_group_names"},
@@ -120,7 +147,6 @@ func resourceCloudStackInstance() *schema.Resource {
 			"security_group_names": {
 				Type:          schema.TypeSet,
 				Optional:      true,
-				ForceNew:      true,
 				Elem:          &schema.Schema{Type: schema.TypeString},
 				Set:           schema.HashString,
 				ConflictsWith: []string{"security_group_ids"},
@@ -242,14 +268,49 @@ func resourceCloudStackInstanceCreate(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
-	// Retrieve the template ID
-	templateid, e := retrieveTemplateID(cs, zone.Id, d.Get("template").(string))
-	if e != nil {
-		return e.Error()
+	// Retrieve the ID of the ISO to attach or deploy from
+	var isoid string
+	if iso, ok := d.GetOk("iso"); ok {
+		isoid, e = retrieveISOID(cs, zone.Id, iso.(string))
+		if e != nil {
+			return e.Error()
+		}
+	}
+
+	// Retrieve the template ID, or deploy from the ISO if no template is supplied
+	template, fromTemplate := d.GetOk("template")
+	templateid := isoid
+	if fromTemplate {
+		templateid, e = retrieveTemplateID(cs, zone.Id, template.(string))
+		if e != nil {
+			return e.Error()
+		}
+	} else if isoid == "" {
+		return fmt.Errorf("Either a template or an iso is required to deploy an instance")
 	}
 
 	// Create a new parameter struct
 	p := cs.VirtualMachine.NewDeployVirtualMachineParams(serviceofferingid, templateid, zone.Id)
+
+	// When deploying from an ISO, the root disk is created from a disk offering
+	if !fromTemplate {
+		diskoffering, ok := d.GetOk("disk_offering")
+		if !ok {
+			return fmt.Errorf("A disk_offering is required to deploy an instance from an ISO")
+		}
+		diskofferingid, e := retrieveID(cs, "disk_offering", diskoffering.(string))
+		if e != nil {
+			return e.Error()
+		}
+		p.SetDiskofferingid(diskofferingid)
+
+		hypervisor, ok := d.GetOk("hypervisor")
+		if !ok {
+			return fmt.Errorf("A hypervisor is required to deploy an instance from an ISO")
+		}
+		p.SetHypervisor(hypervisor.(string))
+	}
+
 	p.SetStartvm(d.Get("start_vm").(bool))
 	vmDetails := make(map[string]string)
 	if details, ok := d.GetOk("details"); ok {
@@ -294,7 +355,12 @@ func resourceCloudStackInstanceCreate(d *schema.ResourceData, meta interface{})
 
 	// If there is a root_disk_size supplied, add it to the parameter struct
 	if rootdisksize, ok := d.GetOk("root_disk_size"); ok {
-		p.SetRootdisksize(int64(rootdisksize.(int)))
+		if fromTemplate {
+			p.SetRootdisksize(int64(rootdisksize.(int)))
+		} else {
+			// The root disk is created from the (custom) disk offering
+			p.SetSize(int64(rootdisksize.(int)))
+		}
 	}
 
 	if d.Get("uefi").(bool) {
@@ -312,6 +378,11 @@ func resourceCloudStackInstanceCreate(d *schema.ResourceData, meta interface{})
 		p.SetIpaddress(ipaddress.(string))
 	}
 
+	// If there is a IPv6 address supplied, add it to the parameter struct
+	if ip6address, ok := d.GetOk("ip6_address"); ok {
+		p.SetIp6address(ip6address.(string))
+	}
+
 	// If there is a group supplied, add it to the parameter struct
 	if group, ok := d.GetOk("group"); ok {
 		p.SetGroup(group.(string))
@@ -416,6 +487,14 @@ func resourceCloudStackInstanceCreate(d *schema.ResourceData, meta interface{})
 		"password": r.Password,
 	})
 
+	// Attach the ISO if the instance is deployed from a template
+	if fromTemplate && isoid != "" {
+		p := cs.ISO.NewAttachIsoParams(isoid, d.Id())
+		if _, err := cs.ISO.AttachIso(p); err != nil {
+			return fmt.Errorf("Error attaching ISO to the new instance %s: %s", name, err)
+		}
+	}
+
 	return resourceCloudStackInstanceRead(d, meta)
 }
 
@@ -447,6 +526,7 @@ func resourceCloudStackInstanceRead(d *schema.ResourceData, meta interface{}) er
 	if len(vm.Nic) > 0 {
 		d.Set("network_id", vm.Nic[0].Networkid)
 		d.Set("ip_address", vm.Nic[0].Ipaddress)
+		d.Set("ip6_address", vm.Nic[0].Ip6address)
 	}
 
 	// Create a new param struct.
@@ -502,7 +582,12 @@ func resourceCloudStackInstanceRead(d *schema.ResourceData, meta interface{}) er
 	d.Set("tags", tagsToMap(vm.Tags))
 
 	setValueOrID(d, "service_offering", vm.Serviceofferingname, vm.Serviceofferingid)
-	setValueOrID(d, "template", vm.Templatename, vm.Templateid)
+	setValueOrID(d, "iso", vm.Isoname, vm.Isoid)
+
+	// Instances deployed from an ISO refer to the ISO as their template
+	if vm.Templateformat != "ISO" {
+		setValueOrID(d, "template", vm.Templatename, vm.Templateid)
+	}
 	setValueOrID(d, "project", vm.Project, vm.Projectid)
 	setValueOrID(d, "zone", vm.Zonename, vm.Zoneid)
 
@@ -555,7 +640,8 @@ func resourceCloudStackInstanceUpdate(d *schema.ResourceData, meta interface{})
 
 	// Attributes that require reboot to update
 	if d.HasChange("name") || d.HasChange("service_offering") || d.HasChange("affinity_group_ids") ||
-		d.HasChange("affinity_group_names") || d.HasChange("keypair") || d.HasChange("keypairs") || d.HasChange("user_data") {
+		d.HasChange("affinity_group_names") || d.HasChange("security_group_ids") || d.HasChange("security_group_names") ||
+		d.HasChange("keypair") || d.HasChange("keypairs") || d.HasChange("user_data") {
 
 		// Before we can actually make these changes, the virtual machine must be stopped
 		_, err := cs.VirtualMachine.StopVirtualMachine(
@@ -649,6 +735,36 @@ func resourceCloudStackInstanceUpdate(d *schema.ResourceData, meta interface{})
 			}
 		}
 
+		// Check if the security groups have changed and if so, update the groups
+		if d.HasChange("security_group_ids") || d.HasChange("security_group_names") {
+			log.Printf("[DEBUG] Security groups changed for %s, starting update", name)
+
+			p := cs.VirtualMachine.NewUpdateVirtualMachineParams(d.Id())
+
+			if sgIDs := d.Get("security_group_ids").(*schema.Set); sgIDs.Len() > 0 {
+				var groups []string
+				for _, group := range sgIDs.List() {
+					groups = append(groups, group.(string))
+				}
+				p.SetSecuritygroupids(groups)
+			}
+
+			if sgNames := d.Get("security_group_names").(*schema.Set); sgNames.Len() > 0 {
+				var groups []string
+				for _, group := range sgNames.List() {
+					groups = append(groups, group.(string))
+				}
+				p.SetSecuritygroupnames(groups)
+			}
+
+			// Update the security groups
+			_, err = cs.VirtualMachine.UpdateVirtualMachine(p)
+			if err != nil {
+				return fmt.Errorf(
+					"Error updating the security groups for instance %s: %s", name, err)
+			}
+		}
+
 		// Check if the keypair has changed and if so, update the keypair
 		if d.HasChange("keypair") || d.HasChange("keypairs") {
 			log.Printf("[DEBUG] SSH keypair(s) changed for %s, starting update", name)
@@ -718,6 +834,35 @@ func resourceCloudStackInstanceUpdate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
+	// Check if the ISO has changed and if so, detach the old and attach the new ISO
+	if d.HasChange("iso") {
+		o, n := d.GetChange("iso")
+
+		if o.(string) != "" {
+			p := cs.ISO.NewDetachIsoParams(d.Id())
+			if _, err := cs.ISO.DetachIso(p); err != nil {
+				return fmt.Errorf("Error detaching ISO from instance %s: %s", name, err)
+			}
+		}
+
+		if n.(string) != "" {
+			zoneid, e := retrieveID(cs, "zone", d.Get("zone").(string))
+			if e != nil {
+				return e.Error()
+			}
+
+			isoid, e := retrieveISOID(cs, zoneid, n.(string))
+			if e != nil {
+				return e.Error()
+			}
+
+			p := cs.ISO.NewAttachIsoParams(isoid, d.Id())
+			if _, err := cs.ISO.AttachIso(p); err != nil {
+				return fmt.Errorf("Error attaching ISO to instance %s: %s", name, err)
+			}
+		}
+	}
+
 	// Check if the tags have changed and if so, update the tags
 	if d.HasChange("tags") {
 		if err := updateTags(cs, d, "UserVm"); err != nil {
@@ -764,6 +909,33 @@ func resourceCloudStackInstanceDelete(d *schema.ResourceData, meta interface{})
 
 	return nil
 }
+
+func resourceCloudStackInstanceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// Instances can be created without security groups
+	if d.Id() == "" {
+		return nil
+	}
+
+	if !d.HasChange("security_group_ids") && !d.HasChange("security_group_names") {
+		return nil
+	}
+
+	if !d.NewValueKnown("security_group_ids") || !d.NewValueKnown("security_group_names") {
+		return nil
+	}
+
+	// CloudStack doesn't remove all security groups of an instance, so an empty
+	// set would never be applied
+	ids := d.Get("security_group_ids").(*schema.Set)
+	names := d.Get("security_group_names").(*schema.Set)
+	if ids.Len() == 0 && names.Len() == 0 {
+		return fmt.Errorf(
+			"At least one security group must remain attached to instance %s", d.Get("name").(string))
+	}
+
+	return nil
+}
+
 func resourceCloudStackInstanceImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	// We set start_vm to true as that matches the default and we assume that
 	// when you need to import an instance it means it is already running.
@@ -295,6 +295,68 @@ func TestAccCloudStackInstance_userData(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackInstance_iso(t *testing.T) {
+	if cloudStackISOURL == "" {
+		t.Skip("This test requires an ISO URL")
+	}
+
+	var instance cloudstack.VirtualMachine
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackInstance_iso,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackInstanceExists(
+						"cloudstack_instance.foobar", &instance),
+					resource.TestCheckResourceAttrPair(
+						"cloudstack_instance.foobar", "iso", "cloudstack_iso.foo", "id"),
+				),
+			},
+
+			{
+				Config: testAccCloudStackInstance_isoDetached,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackInstanceExists(
+						"cloudstack_instance.foobar", &instance),
+					resource.TestCheckResourceAttr(
+						"cloudstack_instance.foobar", "iso", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackInstance_deployFromISO(t *testing.T) {
+	if cloudStackISOURL == "" {
+		t.Skip("This test requires an ISO URL")
+	}
+
+	var instance cloudstack.VirtualMachine
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackInstance_deployFromISO,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackInstanceExists(
+						"cloudstack_instance.foobar", &instance),
+					resource.TestCheckResourceAttrPair(
+						"cloudstack_instance.foobar", "iso", "cloudstack_iso.foo", "id"),
+					resource.TestCheckNoResourceAttr(
+						"cloudstack_instance.foobar", "template"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckCloudStackInstanceExists(
 	n string, instance *cloudstack.VirtualMachine) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
@@ -576,3 +638,84 @@ ${random_bytes.string.base64}
 EOF
 EOFTF
 }`
+
+var testAccCloudStackInstance_iso = fmt.Sprintf(`
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_iso" "foo" {
+  name = "terraform-test-iso"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_instance" "foobar" {
+  name = "terraform-test"
+  display_name = "terraform-test"
+  service_offering= "Small Instance"
+  network_id = cloudstack_network.foo.id
+  template = "CentOS 5.6 (64-bit) no GUI (Simulator)"
+  iso = cloudstack_iso.foo.id
+  zone = "Sandbox-simulator"
+  expunge = true
+}`, cloudStackISOURL)
+
+var testAccCloudStackInstance_isoDetached = fmt.Sprintf(`
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_iso" "foo" {
+  name = "terraform-test-iso"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_instance" "foobar" {
+  name = "terraform-test"
+  display_name = "terraform-test"
+  service_offering= "Small Instance"
+  network_id = cloudstack_network.foo.id
+  template = "CentOS 5.6 (64-bit) no GUI (Simulator)"
+  zone = "Sandbox-simulator"
+  expunge = true
+}`, cloudStackISOURL)
+
+var testAccCloudStackInstance_deployFromISO = fmt.Sprintf(`
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_iso" "foo" {
+  name = "terraform-test-iso"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_instance" "foobar" {
+  name = "terraform-test"
+  display_name = "terraform-test"
+  service_offering= "Small Instance"
+  network_id = cloudstack_network.foo.id
+  iso = cloudstack_iso.foo.id
+  disk_offering = "Small"
+  hypervisor = "Simulator"
+  zone = "Sandbox-simulator"
+  expunge = true
+}`, cloudStackISOURL)
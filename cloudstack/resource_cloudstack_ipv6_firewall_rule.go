@@ -0,0 +1,667 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceCloudStackIPv6FirewallRule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCloudStackIPv6FirewallRuleCreate,
+		Read:   resourceCloudStackIPv6FirewallRuleRead,
+		Update: resourceCloudStackIPv6FirewallRuleUpdate,
+		Delete: resourceCloudStackIPv6FirewallRuleDelete,
+
+		CustomizeDiff: resourceCloudStackIPv6FirewallRuleCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"network_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"managed": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"rule": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cidr_list": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+
+						"dest_cidr_list": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+
+						"protocol": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"icmp_type": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Computed: true,
+						},
+
+						"icmp_code": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Computed: true,
+						},
+
+						"ports": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+
+						"traffic_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "ingress",
+						},
+
+						"uuids": {
+							Type:     schema.TypeMap,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"parallelism": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  2,
+			},
+		},
+	}
+}
+
+func resourceCloudStackIPv6FirewallRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	// Make sure all required parameters are there
+	if err := verifyIPv6FirewallParams(d); err != nil {
+		return err
+	}
+
+	// We need to set this upfront in order to be able to save a partial state
+	d.SetId(d.Get("network_id").(string))
+
+	// Create all rules that are configured
+	if nrs := d.Get("rule").(*schema.Set); nrs.Len() > 0 {
+		// Create an empty schema.Set to hold all rules
+		rules := resourceCloudStackIPv6FirewallRule().Schema["rule"].ZeroValue().(*schema.Set)
+
+		err := createIPv6FirewallRules(d, meta, rules, nrs)
+
+		// We need to update this first to preserve the correct state
+		d.Set("rule", rules)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceCloudStackIPv6FirewallRuleRead(d, meta)
+}
+
+func createIPv6FirewallRules(d *schema.ResourceData, meta interface{}, rules *schema.Set, nrs *schema.Set) error {
+	var errs *multierror.Error
+
+	var wg sync.WaitGroup
+	wg.Add(nrs.Len())
+
+	sem := make(chan struct{}, d.Get("parallelism").(int))
+	for _, rule := range nrs.List() {
+		// Put in a tiny sleep here to avoid DoS'ing the API
+		time.Sleep(500 * time.Millisecond)
+
+		go func(rule map[string]interface{}) {
+			defer wg.Done()
+			sem <- struct{}{}
+
+			// Create a single rule
+			err := createIPv6FirewallRule(d, meta, rule)
+
+			// If we have at least one UUID, we need to save the rule
+			if len(rule["uuids"].(map[string]interface{})) > 0 {
+				rules.Add(rule)
+			}
+
+			if err != nil {
+				errs = multierror.Append(errs, err)
+			}
+
+			<-sem
+		}(rule.(map[string]interface{}))
+	}
+
+	wg.Wait()
+
+	return errs.ErrorOrNil()
+}
+
+func createIPv6FirewallRule(d *schema.ResourceData, meta interface{}, rule map[string]interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	uuids := rule["uuids"].(map[string]interface{})
+
+	// Make sure all required rule parameters are there
+	if err := verifyIPv6FirewallRuleParams(d, rule); err != nil {
+		return err
+	}
+
+	// Create a new parameter struct
+	p := cs.Firewall.NewCreateIpv6FirewallRuleParams(d.Id(), rule["protocol"].(string))
+
+	// Set the traffic type
+	p.SetTraffictype(rule["traffic_type"].(string))
+
+	// Set the CIDR list
+	if rs := rule["cidr_list"].(*schema.Set); rs.Len() > 0 {
+		var cidrList []string
+		for _, cidr := range rs.List() {
+			cidrList = append(cidrList, cidr.(string))
+		}
+		p.SetCidrlist(cidrList)
+	}
+
+	// Set the destination CIDR list
+	if rs := rule["dest_cidr_list"].(*schema.Set); rs.Len() > 0 {
+		var cidrList []string
+		for _, cidr := range rs.List() {
+			cidrList = append(cidrList, cidr.(string))
+		}
+		p.SetDestcidrlist(cidrList)
+	}
+
+	// If the protocol is ICMP set the needed ICMP parameters
+	if _, ok := uuids["icmp"]; !ok && rule["protocol"].(string) == "icmp" {
+		p.SetIcmptype(rule["icmp_type"].(int))
+		p.SetIcmpcode(rule["icmp_code"].(int))
+
+		r, err := cs.Firewall.CreateIpv6FirewallRule(p)
+		if err != nil {
+			return err
+		}
+
+		uuids["icmp"] = r.Id
+		rule["uuids"] = uuids
+	}
+
+	// If the protocol is ALL set the needed parameters
+	if _, ok := uuids["all"]; !ok && rule["protocol"].(string) == "all" {
+		r, err := cs.Firewall.CreateIpv6FirewallRule(p)
+		if err != nil {
+			return err
+		}
+
+		uuids["all"] = r.Id
+		rule["uuids"] = uuids
+	}
+
+	// If protocol is TCP or UDP, loop through all ports
+	if rule["protocol"].(string) == "tcp" || rule["protocol"].(string) == "udp" {
+		if ps := rule["ports"].(*schema.Set); ps.Len() > 0 {
+
+			// Create an empty schema.Set to hold all processed ports
+			ports := &schema.Set{F: schema.HashString}
+
+			for _, port := range ps.List() {
+				if _, ok := uuids[port.(string)]; ok {
+					ports.Add(port)
+					rule["ports"] = ports
+					continue
+				}
+
+				m := splitPorts.FindStringSubmatch(port.(string))
+
+				startPort, err := strconv.Atoi(m[1])
+				if err != nil {
+					return err
+				}
+
+				endPort := startPort
+				if m[2] != "" {
+					endPort, err = strconv.Atoi(m[2])
+					if err != nil {
+						return err
+					}
+				}
+
+				p.SetStartport(startPort)
+				p.SetEndport(endPort)
+
+				r, err := cs.Firewall.CreateIpv6FirewallRule(p)
+				if err != nil {
+					return err
+				}
+
+				ports.Add(port)
+				rule["ports"] = ports
+
+				uuids[port.(string)] = r.Id
+				rule["uuids"] = uuids
+			}
+		}
+	}
+
+	return nil
+}
+
+func resourceCloudStackIPv6FirewallRuleRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Get all the rules from the running environment
+	p := cs.Firewall.NewListIpv6FirewallRulesParams()
+	p.SetNetworkid(d.Id())
+	p.SetListall(true)
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return err
+	}
+
+	l, err := cs.Firewall.ListIpv6FirewallRules(p)
+	if err != nil {
+		return err
+	}
+
+	// Make a map of all the rules so we can easily find a rule
+	ruleMap := make(map[string]*cloudstack.Ipv6FirewallRule, l.Count)
+	for _, r := range l.Ipv6FirewallRules {
+		ruleMap[r.Id] = r
+	}
+
+	// Create an empty schema.Set to hold all rules
+	rules := resourceCloudStackIPv6FirewallRule().Schema["rule"].ZeroValue().(*schema.Set)
+
+	// Read all rules that are configured
+	if rs := d.Get("rule").(*schema.Set); rs.Len() > 0 {
+		for _, rule := range rs.List() {
+			rule := rule.(map[string]interface{})
+			uuids := rule["uuids"].(map[string]interface{})
+
+			if rule["protocol"].(string) == "icmp" || rule["protocol"].(string) == "all" {
+				id, ok := uuids[rule["protocol"].(string)]
+				if !ok {
+					continue
+				}
+
+				// Get the rule
+				r, ok := ruleMap[id.(string)]
+				if !ok {
+					delete(uuids, rule["protocol"].(string))
+					continue
+				}
+
+				// Delete the known rule so only unknown rules remain in the ruleMap
+				delete(ruleMap, id.(string))
+
+				// Update the values
+				rule["protocol"] = r.Protocol
+				if rule["cidr_list"].(*schema.Set).Len() > 0 {
+					rule["cidr_list"] = ipv6FirewallCIDRs(r.Cidrlist)
+				}
+				rules.Add(rule)
+			}
+
+			// If protocol is tcp or udp, loop through all ports
+			if rule["protocol"].(string) == "tcp" || rule["protocol"].(string) == "udp" {
+				if ps := rule["ports"].(*schema.Set); ps.Len() > 0 {
+
+					// Create an empty schema.Set to hold all ports
+					ports := &schema.Set{F: schema.HashString}
+
+					// Loop through all ports and retrieve their info
+					for _, port := range ps.List() {
+						id, ok := uuids[port.(string)]
+						if !ok {
+							continue
+						}
+
+						// Get the rule
+						r, ok := ruleMap[id.(string)]
+						if !ok {
+							delete(uuids, port.(string))
+							continue
+						}
+
+						// Delete the known rule so only unknown rules remain in the ruleMap
+						delete(ruleMap, id.(string))
+
+						// Update the values
+						rule["protocol"] = r.Protocol
+						if rule["cidr_list"].(*schema.Set).Len() > 0 {
+							rule["cidr_list"] = ipv6FirewallCIDRs(r.Cidrlist)
+						}
+						ports.Add(port)
+					}
+
+					// If there is at least one port found, add this rule to the rules set
+					if ports.Len() > 0 {
+						rule["ports"] = ports
+						rules.Add(rule)
+					}
+				}
+			}
+		}
+	}
+
+	// If this is a managed firewall, add all unknown rules into dummy rules
+	managed := d.Get("managed").(bool)
+	if managed && len(ruleMap) > 0 {
+		for uuid := range ruleMap {
+			// Make a dummy rule to hold the unknown UUID
+			rule := map[string]interface{}{
+				"cidr_list": &schema.Set{F: schema.HashString},
+				"protocol":  uuid,
+				"uuids":     map[string]interface{}{uuid: uuid},
+			}
+
+			// Add the dummy rule to the rules set
+			rules.Add(rule)
+		}
+	}
+
+	if rules.Len() > 0 {
+		d.Set("rule", rules)
+	} else if !managed {
+		d.SetId("")
+	}
+
+	return nil
+}
+
+// ipv6FirewallCIDRs returns a set with all CIDR's in a comma separated list
+func ipv6FirewallCIDRs(cidrList string) *schema.Set {
+	cidrs := &schema.Set{F: schema.HashString}
+	for _, cidr := range strings.Split(cidrList, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs.Add(cidr)
+		}
+	}
+	return cidrs
+}
+
+func resourceCloudStackIPv6FirewallRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Make sure all required parameters are there
+	if err := verifyIPv6FirewallParams(d); err != nil {
+		return err
+	}
+
+	// Check if the rule set as a whole has changed
+	if d.HasChange("rule") {
+		o, n := d.GetChange("rule")
+		ors := o.(*schema.Set).Difference(n.(*schema.Set))
+		nrs := n.(*schema.Set).Difference(o.(*schema.Set))
+
+		// We need to start with a rule set containing all the rules we
+		// already have and want to keep. Any rules that are not deleted
+		// correctly and any newly created rules, will be added to this
+		// set to make sure we end up in a consistent state
+		rules := o.(*schema.Set).Intersection(n.(*schema.Set))
+
+		// Pair changed rules that only differ in their ports, so only the
+		// ports that are actually added or removed are touched
+		pairRules(ors, nrs, ipv6FirewallRuleIdentity, ruleUnits)
+
+		// First loop through all the new rules and create (before destroy) them
+		if nrs.Len() > 0 {
+			err := createIPv6FirewallRules(d, meta, rules, nrs)
+
+			// We need to update this first to preserve the correct state
+			d.Set("rule", rules)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// Then loop through all the old rules and delete them
+		if ors.Len() > 0 {
+			err := deleteIPv6FirewallRules(d, meta, rules, ors)
+
+			// We need to update this first to preserve the correct state
+			d.Set("rule", rules)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return resourceCloudStackIPv6FirewallRuleRead(d, meta)
+}
+
+// ipv6FirewallRuleIdentity returns a key identifying a rule by everything but
+// its ports, as the CIDR lists are part of every IPv6 firewall rule created for
+// a port.
+func ipv6FirewallRuleIdentity(rule map[string]interface{}) string {
+	var cidrs, destCIDRs []string
+	for _, cidr := range rule["cidr_list"].(*schema.Set).List() {
+		cidrs = append(cidrs, cidr.(string))
+	}
+	for _, cidr := range rule["dest_cidr_list"].(*schema.Set).List() {
+		destCIDRs = append(destCIDRs, cidr.(string))
+	}
+
+	return fmt.Sprintf("%s|%d|%d|%s|%s|%s",
+		rule["protocol"].(string),
+		rule["icmp_type"].(int),
+		rule["icmp_code"].(int),
+		rule["traffic_type"].(string),
+		sortedCIDRs(strings.Join(cidrs, ",")),
+		sortedCIDRs(strings.Join(destCIDRs, ",")),
+	)
+}
+
+func resourceCloudStackIPv6FirewallRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	// Create an empty rule set to hold all rules that where
+	// not deleted correctly
+	rules := resourceCloudStackIPv6FirewallRule().Schema["rule"].ZeroValue().(*schema.Set)
+
+	// Delete all rules
+	if ors := d.Get("rule").(*schema.Set); ors.Len() > 0 {
+		err := deleteIPv6FirewallRules(d, meta, rules, ors)
+
+		// We need to update this first to preserve the correct state
+		d.Set("rule", rules)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteIPv6FirewallRules(d *schema.ResourceData, meta interface{}, rules *schema.Set, ors *schema.Set) error {
+	var errs *multierror.Error
+
+	var wg sync.WaitGroup
+	wg.Add(ors.Len())
+
+	sem := make(chan struct{}, d.Get("parallelism").(int))
+	for _, rule := range ors.List() {
+		// Put a sleep here to avoid DoS'ing the API
+		time.Sleep(500 * time.Millisecond)
+
+		go func(rule map[string]interface{}) {
+			defer wg.Done()
+			sem <- struct{}{}
+
+			// Delete a single rule
+			err := deleteIPv6FirewallRule(d, meta, rule)
+
+			// If we have at least one UUID, we need to save the rule
+			if len(rule["uuids"].(map[string]interface{})) > 0 {
+				rules.Add(rule)
+			}
+
+			if err != nil {
+				errs = multierror.Append(errs, err)
+			}
+
+			<-sem
+		}(rule.(map[string]interface{}))
+	}
+
+	wg.Wait()
+
+	return errs.ErrorOrNil()
+}
+
+func deleteIPv6FirewallRule(d *schema.ResourceData, meta interface{}, rule map[string]interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	uuids := rule["uuids"].(map[string]interface{})
+
+	for k, id := range uuids {
+		// We don't care about the count here, so just continue
+		if k == "%" {
+			continue
+		}
+
+		// Create the parameter struct
+		p := cs.Firewall.NewDeleteIpv6FirewallRuleParams(id.(string))
+
+		// Delete the rule
+		if _, err := cs.Firewall.DeleteIpv6FirewallRule(p); err != nil {
+
+			// This is a very poor way to be told the ID does no longer exist :(
+			if strings.Contains(err.Error(), fmt.Sprintf(
+				"Invalid parameter id value=%s due to incorrect long value format, "+
+					"or entity does not exist", id.(string))) {
+				delete(uuids, k)
+				rule["uuids"] = uuids
+				continue
+			}
+
+			return err
+		}
+
+		// Delete the UUID of this rule
+		delete(uuids, k)
+		rule["uuids"] = uuids
+	}
+
+	return nil
+}
+
+func resourceCloudStackIPv6FirewallRuleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	return verifyRuleSet(d, "cloudstack_ipv6_firewall_rule", "rule", "ingress")
+}
+
+func verifyIPv6FirewallParams(d *schema.ResourceData) error {
+	managed := d.Get("managed").(bool)
+	_, rules := d.GetOk("rule")
+
+	if !rules && !managed {
+		return fmt.Errorf(
+			"You must supply at least one 'rule' when not using the 'managed' firewall feature")
+	}
+
+	return nil
+}
+
+func verifyIPv6FirewallRuleParams(d *schema.ResourceData, rule map[string]interface{}) error {
+	for _, key := range []string{"cidr_list", "dest_cidr_list"} {
+		for _, cidr := range rule[key].(*schema.Set).List() {
+			ip, _, err := net.ParseCIDR(cidr.(string))
+			if err != nil || ip.To4() != nil {
+				return fmt.Errorf("%q is not a valid IPv6 CIDR", cidr.(string))
+			}
+		}
+	}
+
+	protocol := rule["protocol"].(string)
+	switch protocol {
+	case "icmp":
+		if _, ok := rule["icmp_type"]; !ok {
+			return fmt.Errorf(
+				"Parameter icmp_type is a required parameter when using protocol 'icmp'")
+		}
+		if _, ok := rule["icmp_code"]; !ok {
+			return fmt.Errorf(
+				"Parameter icmp_code is a required parameter when using protocol 'icmp'")
+		}
+	case "all":
+		if ports, _ := rule["ports"].(*schema.Set); ports.Len() > 0 {
+			return fmt.Errorf(
+				"Parameter ports is not required when using protocol 'all'")
+		}
+	case "tcp", "udp":
+		if ports, ok := rule["ports"].(*schema.Set); ok && ports.Len() > 0 {
+			for _, port := range ports.List() {
+				m := splitPorts.FindStringSubmatch(port.(string))
+				if m == nil {
+					return fmt.Errorf(
+						"%q is not a valid port value. Valid options are '80' or '80-90'", port.(string))
+				}
+			}
+		} else {
+			return fmt.Errorf(
+				"Parameter ports is a required parameter when using protocol 'tcp' or 'udp'")
+		}
+	default:
+		return fmt.Errorf(
+			"%q is not a valid protocol. Valid options are 'tcp', 'udp', 'icmp' and 'all'", protocol)
+	}
+
+	traffic := rule["traffic_type"].(string)
+	if traffic != "ingress" && traffic != "egress" {
+		return fmt.Errorf(
+			"Parameter traffic_type only accepts 'ingress' or 'egress' as values")
+	}
+
+	return nil
+}
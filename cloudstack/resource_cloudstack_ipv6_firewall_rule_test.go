@@ -0,0 +1,184 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccCloudStackIPv6FirewallRule_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackIPv6FirewallRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackIPv6FirewallRule_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackIPv6FirewallRulesExist("cloudstack_ipv6_firewall_rule.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_ipv6_firewall_rule.foo", "rule.#", "1"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_ipv6_firewall_rule.foo", "rule.0.cidr_list.0", "2001:db8::/64"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_ipv6_firewall_rule.foo", "rule.0.protocol", "tcp"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_ipv6_firewall_rule.foo", "rule.0.ports.0", "443"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackIPv6FirewallRule_update(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackIPv6FirewallRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackIPv6FirewallRule_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackIPv6FirewallRulesExist("cloudstack_ipv6_firewall_rule.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_ipv6_firewall_rule.foo", "rule.#", "1"),
+				),
+			},
+
+			{
+				Config: testAccCloudStackIPv6FirewallRule_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackIPv6FirewallRulesExist("cloudstack_ipv6_firewall_rule.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_ipv6_firewall_rule.foo", "rule.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckCloudStackIPv6FirewallRulesExist(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No firewall ID is set")
+		}
+
+		for k, id := range rs.Primary.Attributes {
+			if !strings.Contains(k, ".uuids.") || strings.HasSuffix(k, ".uuids.%") {
+				continue
+			}
+
+			cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+			_, count, err := cs.Firewall.GetIpv6FirewallRuleByID(id)
+
+			if err != nil {
+				return err
+			}
+
+			if count == 0 {
+				return fmt.Errorf("IPv6 firewall rule for %s not found", k)
+			}
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackIPv6FirewallRuleDestroy(s *terraform.State) error {
+	cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "cloudstack_ipv6_firewall_rule" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No network ID is set")
+		}
+
+		for k, id := range rs.Primary.Attributes {
+			if !strings.Contains(k, ".uuids.") || strings.HasSuffix(k, ".uuids.%") {
+				continue
+			}
+
+			_, _, err := cs.Firewall.GetIpv6FirewallRuleByID(id)
+			if err == nil {
+				return fmt.Errorf("IPv6 firewall rule %s still exists", rs.Primary.ID)
+			}
+		}
+	}
+
+	return nil
+}
+
+const testAccCloudStackIPv6FirewallRule_basic = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_ipv6_firewall_rule" "foo" {
+  network_id = cloudstack_network.foo.id
+
+  rule {
+    cidr_list = ["2001:db8::/64"]
+    protocol = "tcp"
+    ports = ["443"]
+  }
+}`
+
+const testAccCloudStackIPv6FirewallRule_update = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_ipv6_firewall_rule" "foo" {
+  network_id = cloudstack_network.foo.id
+
+  rule {
+    cidr_list = ["2001:db8::/64", "2001:db8:1::/64"]
+    protocol = "tcp"
+    ports = ["443"]
+  }
+
+  rule {
+    dest_cidr_list = ["::/0"]
+    protocol = "all"
+    traffic_type = "egress"
+  }
+}`
@@ -0,0 +1,506 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceCloudStackISO() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCloudStackISOCreate,
+		Read:   resourceCloudStackISORead,
+		Update: resourceCloudStackISOUpdate,
+		Delete: resourceCloudStackISODelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceCloudStackISOImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"display_text": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"url": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{"url", "source_file"},
+				DiffSuppressFunc: suppressImportedISOSource,
+			},
+
+			"source_file": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressImportedISOSource,
+			},
+
+			"os_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"bootable": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"zone": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"zones"},
+			},
+
+			"zones": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ForceNew:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Set:           schema.HashString,
+				ConflictsWith: []string{"zone"},
+			},
+
+			"is_extractable": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"is_featured": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"is_public": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"is_ready": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"is_ready_timeout": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  300,
+			},
+
+			"zone_status": imageZoneStatusSchema(),
+
+			"tags": tagsSchema(),
+		},
+	}
+}
+
+func resourceCloudStackISOCreate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if err := verifyISOParams(d); err != nil {
+		return err
+	}
+
+	name := d.Get("name").(string)
+
+	// Compute/set the display text
+	displaytext := d.Get("display_text").(string)
+	if displaytext == "" {
+		displaytext = name
+	}
+
+	// Retrieve the zone IDs, the ISO is registered in the first zone and
+	// then copied to any other zones
+	zoneids, err := isoZoneIDs(cs, d)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the os_type ID
+	var ostypeid string
+	if v, ok := d.GetOk("os_type"); ok {
+		id, e := retrieveID(cs, "os_type", v.(string))
+		if e != nil {
+			return e.Error()
+		}
+		ostypeid = id
+	}
+
+	if file, ok := d.GetOk("source_file"); ok {
+		// Create a new parameter struct
+		p := cs.ISO.NewGetUploadParamsForIsoParams("ISO", name, zoneids[0])
+		p.SetDisplaytext(displaytext)
+		p.SetBootable(d.Get("bootable").(bool))
+
+		if ostypeid != "" {
+			p.SetOstypeid(ostypeid)
+		}
+
+		if v, ok := d.GetOk("is_extractable"); ok {
+			p.SetIsextractable(v.(bool))
+		}
+
+		if v, ok := d.GetOk("is_featured"); ok {
+			p.SetIsfeatured(v.(bool))
+		}
+
+		if v, ok := d.GetOk("is_public"); ok {
+			p.SetIspublic(v.(bool))
+		}
+
+		// Let secondary storage verify the uploaded file
+		checksum, err := fileChecksum(file.(string))
+		if err != nil {
+			return err
+		}
+		p.SetChecksum(checksum)
+
+		// If there is a project supplied, we retrieve and set the project id
+		if err := setProjectid(p, cs, d); err != nil {
+			return err
+		}
+
+		// Request the parameters needed to upload the ISO
+		r, err := cs.ISO.GetUploadParamsForIso(p)
+		if err != nil {
+			return fmt.Errorf("Error creating ISO %s: %s", name, err)
+		}
+
+		d.SetId(r.Id)
+
+		err = uploadFile(file.(string), &uploadParams{
+			postURL:   r.PostURL,
+			expires:   r.Expires,
+			metadata:  r.Metadata,
+			signature: r.Signature,
+		})
+		if err != nil {
+			return fmt.Errorf("Error uploading ISO %s: %s", name, err)
+		}
+	} else {
+		// Create a new parameter struct
+		p := cs.ISO.NewRegisterIsoParams(displaytext, name, d.Get("url").(string), zoneids[0])
+		p.SetBootable(d.Get("bootable").(bool))
+
+		if ostypeid != "" {
+			p.SetOstypeid(ostypeid)
+		}
+
+		if v, ok := d.GetOk("is_extractable"); ok {
+			p.SetIsextractable(v.(bool))
+		}
+
+		if v, ok := d.GetOk("is_featured"); ok {
+			p.SetIsfeatured(v.(bool))
+		}
+
+		if v, ok := d.GetOk("is_public"); ok {
+			p.SetIspublic(v.(bool))
+		}
+
+		// If there is a project supplied, we retrieve and set the project id
+		if err := setProjectid(p, cs, d); err != nil {
+			return err
+		}
+
+		// Register the new ISO
+		r, err := cs.ISO.RegisterIso(p)
+		if err != nil {
+			return fmt.Errorf("Error creating ISO %s: %s", name, err)
+		}
+
+		d.SetId(r.Id)
+	}
+
+	// Set tags if necessary
+	if err := setTags(cs, d, "ISO"); err != nil {
+		return fmt.Errorf("Error setting tags on the ISO %s: %s", name, err)
+	}
+
+	// Wait until the ISO is ready in the first zone, so it can be copied
+	// to the other zones
+	if len(zoneids) > 1 {
+		if err := waitForImageReady(d, meta, "ISO", resourceCloudStackISORead, zoneids[0]); err != nil {
+			return err
+		}
+
+		p := cs.ISO.NewCopyIsoParams(d.Id())
+		p.SetSourcezoneid(zoneids[0])
+		p.SetDestzoneids(zoneids[1:])
+
+		if _, err := cs.ISO.CopyIso(p); err != nil {
+			return fmt.Errorf("Error copying ISO %s: %s", name, err)
+		}
+	}
+
+	// Wait until the ISO is ready to use, or timeout with an error...
+	return waitForImageReady(d, meta, "ISO", resourceCloudStackISORead)
+}
+
+func resourceCloudStackISORead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Get the ISO details for all zones
+	p := cs.ISO.NewListIsosParams()
+	p.SetId(d.Id())
+	p.SetIsofilter("executable")
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return err
+	}
+
+	r, err := cs.ISO.ListIsos(p)
+	if err != nil {
+		return err
+	}
+
+	if r.Count == 0 {
+		log.Printf("[DEBUG] ISO %s no longer exists", d.Get("name").(string))
+		d.SetId("")
+		return nil
+	}
+
+	// Retrieve the IDs of the zones we have to wait for
+	var zoneids []string
+	_, multi := d.GetOk("zones")
+	if v, ok := d.GetOk("zone"); multi || (ok && !isAllZones(v.(string))) {
+		zoneids, err = isoZoneIDs(cs, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	iso := r.Isos[0]
+	zones := make([]imageZone, 0, len(r.Isos))
+	for _, i := range r.Isos {
+		zones = append(zones, imageZone{i.Zoneid, i.Zonename, i.Isready, i.Status})
+		if len(zoneids) == 1 && i.Zoneid == zoneids[0] {
+			iso = i
+		}
+	}
+
+	d.Set("name", iso.Name)
+	d.Set("display_text", iso.Displaytext)
+	d.Set("bootable", iso.Bootable)
+	d.Set("is_extractable", iso.Isextractable)
+	d.Set("is_featured", iso.Isfeatured)
+	d.Set("is_public", iso.Ispublic)
+	d.Set("is_ready", setImageZoneStatus(d, zones, zoneids))
+	d.Set("tags", tagsToMap(iso.Tags))
+
+	setValueOrID(d, "os_type", iso.Ostypename, iso.Ostypeid)
+	setValueOrID(d, "project", iso.Project, iso.Projectid)
+
+	if v, ok := d.GetOk("zone"); ok && !isAllZones(v.(string)) {
+		setValueOrID(d, "zone", iso.Zonename, iso.Zoneid)
+	}
+
+	return nil
+}
+
+func resourceCloudStackISOUpdate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	name := d.Get("name").(string)
+
+	if d.HasChanges("name", "display_text", "os_type", "bootable") {
+		// Create a new parameter struct
+		p := cs.ISO.NewUpdateIsoParams(d.Id())
+
+		if d.HasChange("name") {
+			p.SetName(name)
+		}
+
+		if d.HasChange("display_text") {
+			p.SetDisplaytext(d.Get("display_text").(string))
+		}
+
+		if d.HasChange("os_type") {
+			ostypeid, e := retrieveID(cs, "os_type", d.Get("os_type").(string))
+			if e != nil {
+				return e.Error()
+			}
+			p.SetOstypeid(ostypeid)
+		}
+
+		if d.HasChange("bootable") {
+			p.SetBootable(d.Get("bootable").(bool))
+		}
+
+		_, err := cs.ISO.UpdateIso(p)
+		if err != nil {
+			return fmt.Errorf("Error updating ISO %s: %s", name, err)
+		}
+	}
+
+	if d.HasChange("tags") {
+		if err := updateTags(cs, d, "ISO"); err != nil {
+			return fmt.Errorf("Error updating tags on ISO %s: %s", name, err)
+		}
+	}
+
+	return resourceCloudStackISORead(d, meta)
+}
+
+func resourceCloudStackISODelete(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Create a new parameter struct
+	p := cs.ISO.NewDeleteIsoParams(d.Id())
+
+	// Delete the ISO
+	log.Printf("[INFO] Deleting ISO: %s", d.Get("name").(string))
+	_, err := cs.ISO.DeleteIso(p)
+	if err != nil {
+		// This is a very poor way to be told the ID does no longer exist :(
+		if strings.Contains(err.Error(), fmt.Sprintf(
+			"Invalid parameter id value=%s due to incorrect long value format, "+
+				"or entity does not exist", d.Id())) {
+			return nil
+		}
+
+		return fmt.Errorf("Error deleting ISO %s: %s", d.Get("name").(string), err)
+	}
+
+	return nil
+}
+
+func resourceCloudStackISOImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if _, err := importStatePassthrough(d, meta); err != nil {
+		return nil, err
+	}
+
+	p := cs.ISO.NewListIsosParams()
+	p.SetId(d.Id())
+	p.SetIsofilter("executable")
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return nil, err
+	}
+
+	r, err := cs.ISO.ListIsos(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// The zones are only read back when they are set, so set them to the
+	// zones the ISO is available in
+	if r.Count == 1 {
+		d.Set("zone", r.Isos[0].Zonename)
+	} else if r.Count > 1 {
+		var zones []string
+		for _, iso := range r.Isos {
+			zones = append(zones, iso.Zonename)
+		}
+		d.Set("zones", zones)
+	}
+
+	d.Set("is_ready_timeout", 300)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// suppressImportedISOSource suppresses the diff of the url and source_file of
+// an imported ISO, as the source of an ISO cannot be read back.
+func suppressImportedISOSource(k, old, new string, d *schema.ResourceData) bool {
+	url, _ := d.GetChange("url")
+	file, _ := d.GetChange("source_file")
+	return d.Id() != "" && url.(string) == "" && file.(string) == ""
+}
+
+// isoZoneIDs returns the IDs of the configured zones of the ISO, or -1 when
+// the ISO should be available in all zones.
+func isoZoneIDs(cs *cloudstack.CloudStackClient, d *schema.ResourceData) ([]string, error) {
+	if v, ok := d.GetOk("zones"); ok {
+		var zoneids []string
+		for _, zone := range v.(*schema.Set).List() {
+			zoneid, e := retrieveID(cs, "zone", zone.(string))
+			if e != nil {
+				return nil, e.Error()
+			}
+			zoneids = append(zoneids, zoneid)
+		}
+		return zoneids, nil
+	}
+
+	zone := d.Get("zone").(string)
+	if isAllZones(zone) {
+		return []string{"-1"}, nil
+	}
+
+	zoneid, e := retrieveID(cs, "zone", zone)
+	if e != nil {
+		return nil, e.Error()
+	}
+
+	return []string{zoneid}, nil
+}
+
+func verifyISOParams(d *schema.ResourceData) error {
+	_, zone := d.GetOk("zone")
+	_, zones := d.GetOk("zones")
+	if !zone && !zones {
+		return fmt.Errorf("You must supply a zone or zones to register the ISO in")
+	}
+
+	if _, ok := d.GetOk("source_file"); ok && isAllZones(d.Get("zone").(string)) {
+		return fmt.Errorf(
+			"Uploading an ISO from a source_file into all zones is not supported; use zones instead")
+	}
+
+	if d.Get("bootable").(bool) {
+		if _, ok := d.GetOk("os_type"); !ok {
+			return fmt.Errorf("An os_type is required for a bootable ISO")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccCloudStackISO_basic(t *testing.T) {
+	if cloudStackISOURL == "" {
+		t.Skip("This test requires an ISO URL")
+	}
+
+	var iso cloudstack.Iso
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackISODestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackISO_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackISOExists("cloudstack_iso.foo", &iso),
+					testAccCheckCloudStackISOBasicAttributes(&iso),
+					resource.TestCheckResourceAttr(
+						"cloudstack_iso.foo", "is_ready", "true"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_iso.foo", "tags.terraform-tag", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackISO_update(t *testing.T) {
+	if cloudStackISOURL == "" {
+		t.Skip("This test requires an ISO URL")
+	}
+
+	var iso cloudstack.Iso
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackISODestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackISO_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackISOExists("cloudstack_iso.foo", &iso),
+					testAccCheckCloudStackISOBasicAttributes(&iso),
+				),
+			},
+
+			{
+				Config: testAccCloudStackISO_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackISOExists("cloudstack_iso.foo", &iso),
+					resource.TestCheckResourceAttr(
+						"cloudstack_iso.foo", "display_text", "terraform-updated"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_iso.foo", "bootable", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackISO_import(t *testing.T) {
+	if cloudStackISOURL == "" {
+		t.Skip("This test requires an ISO URL")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackISODestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackISO_basic,
+			},
+
+			{
+				ResourceName:            "cloudstack_iso.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"url"},
+			},
+		},
+	})
+}
+
+func TestImageReadyInZones(t *testing.T) {
+	d := resourceCloudStackISO().TestResourceData()
+
+	// An ISO registered for two zones that isn't copied to the second zone yet
+	zoneids := []string{"zone-1", "zone-2"}
+	zones := []imageZone{{"zone-1", "Zone 1", true, "Successfully Installed"}}
+
+	if setImageZoneStatus(d, zones, zoneids) {
+		t.Fatalf("expected the ISO not to be ready in all zones")
+	}
+
+	status := d.Get("zone_status").([]interface{})
+	if !imageReadyInZones(status, zoneids[:1]) {
+		t.Fatalf("expected the ISO to be ready in the source zone")
+	}
+	if imageReadyInZones(status, zoneids) {
+		t.Fatalf("expected the ISO not to be ready in the destination zone")
+	}
+	if imageReadyInZones(status, nil) {
+		t.Fatalf("expected the ISO not to be ready without zone IDs")
+	}
+}
+
+func testAccCheckCloudStackISOExists(n string, iso *cloudstack.Iso) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No ISO ID is set")
+		}
+
+		cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+		i, _, err := cs.ISO.GetIsoByID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if i.Id != rs.Primary.ID {
+			return fmt.Errorf("ISO not found")
+		}
+
+		*iso = *i
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackISOBasicAttributes(iso *cloudstack.Iso) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+
+		if iso.Name != "terraform-test" {
+			return fmt.Errorf("Bad name: %s", iso.Name)
+		}
+
+		if !iso.Bootable {
+			return fmt.Errorf("Bad bootable: %t", iso.Bootable)
+		}
+
+		if iso.Ostypename != "CentOS 5.6 (64-bit)" {
+			return fmt.Errorf("Bad os type: %s", iso.Ostypename)
+		}
+
+		if iso.Zonename != "Sandbox-simulator" {
+			return fmt.Errorf("Bad zone: %s", iso.Zonename)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackISODestroy(s *terraform.State) error {
+	cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "cloudstack_iso" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No ISO ID is set")
+		}
+
+		_, _, err := cs.ISO.GetIsoByID(rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("ISO %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+var testAccCloudStackISO_basic = fmt.Sprintf(`
+resource "cloudstack_iso" "foo" {
+  name = "terraform-test"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+  tags = {
+    terraform-tag = "true"
+  }
+}`, cloudStackISOURL)
+
+var testAccCloudStackISO_update = fmt.Sprintf(`
+resource "cloudstack_iso" "foo" {
+  name = "terraform-test"
+  display_text = "terraform-updated"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  bootable = false
+  zone = "Sandbox-simulator"
+  tags = {
+    terraform-tag = "true"
+  }
+}`, cloudStackISOURL)
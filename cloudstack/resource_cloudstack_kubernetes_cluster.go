@@ -20,11 +20,15 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -38,6 +42,15 @@ func resourceCloudStackKubernetesCluster() *schema.Resource {
 			State: importStatePassthrough,
 		},
 
+		CustomizeDiff: resourceCloudStackKubernetesClusterCustomizeDiff,
+
+		// Only used for requests that use node pools, all other requests
+		// use the timeout of the provider
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(15 * time.Minute),
+			Update: schema.DefaultTimeout(15 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -87,9 +100,18 @@ func resourceCloudStackKubernetesCluster() *schema.Resource {
 				Type:     schema.TypeInt,
 				Optional: true,
 				Computed: true,
-				ForceNew: true, // For now
+				ForceNew: true, // CKS cannot scale the control plane of a cluster
+			},
+
+			"scale_in_node_ids": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
 			},
 
+			"node_pool": kubernetesNodePoolSchema(),
+
 			"description": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -114,6 +136,15 @@ func resourceCloudStackKubernetesCluster() *schema.Resource {
 				Computed: true,
 			},
 
+			"kube_config": {
+				Type:      schema.TypeList,
+				Computed:  true,
+				Sensitive: true,
+				Elem: &schema.Resource{
+					Schema: kubeConfigSchema(),
+				},
+			},
+
 			"state": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -161,6 +192,31 @@ func resourceCloudStackKubernetesClusterCreate(d *schema.ResourceData, meta inte
 		return e.Error()
 	}
 
+	// Node pools are created through a custom request
+	if _, ok := d.GetOk("node_pool"); ok {
+		log.Printf("[DEBUG] Creating Kubernetes Cluster %s with node pools", name)
+		id, err := createKubernetesClusterWithNodePools(cs, d, kubernetesVersionID, serviceOfferingID, zoneID)
+		if err != nil {
+			// Keep a cluster that failed to start in the state, so it is
+			// tainted and cleaned up instead of being orphaned
+			if id != "" {
+				d.SetId(id)
+			}
+			return err
+		}
+
+		log.Printf("[DEBUG] Kubernetes Cluster %s successfully created", name)
+		d.SetId(id)
+
+		if _, ok := d.GetOk("autoscaling_enabled"); ok {
+			if err := autoscaleKubernetesCluster(d, meta); err != nil {
+				return err
+			}
+		}
+
+		return resourceCloudStackKubernetesClusterRead(d, meta)
+	}
+
 	// Create a new parameter struct
 	p := cs.Kubernetes.NewCreateKubernetesClusterParams(name, kubernetesVersionID, name, serviceOfferingID, size, zoneID)
 
@@ -244,6 +300,21 @@ func resourceCloudStackKubernetesClusterRead(d *schema.ResourceData, meta interf
 	setValueOrID(d, "project", cluster.Project, cluster.Projectid)
 	setValueOrID(d, "zone", cluster.Zonename, cluster.Zoneid)
 
+	if err := setKubernetesNodePools(cs, d); err != nil {
+		return err
+	}
+
+	// The config can only be retrieved while the cluster is running, so we
+	// keep the last known config when the cluster is in any other state
+	if cluster.State == "Running" {
+		config, err := getKubernetesClusterConfig(cs, cluster.Id)
+		if err != nil {
+			log.Printf("[WARN] %s", err)
+		} else {
+			d.Set("kube_config", []interface{}{config})
+		}
+	}
+
 	return nil
 }
 
@@ -261,17 +332,57 @@ func resourceCloudStackKubernetesClusterUpdate(d *schema.ResourceData, meta inte
 	cs := meta.(*cloudstack.CloudStackClient)
 
 	if d.HasChange("service_offering") || d.HasChange("size") {
-		p := cs.Kubernetes.NewScaleKubernetesClusterParams(d.Id())
-		serviceOfferingID, e := retrieveID(cs, "service_offering", d.Get("service_offering").(string))
-		if e != nil {
-			return e.Error()
+		o, n := d.GetChange("size")
+		nodeIDs := d.Get("scale_in_node_ids").(*schema.Set)
+
+		// Specific nodes cannot be removed while changing the service
+		// offering, so first scale the service offering of the current nodes
+		size := n.(int)
+		if n.(int) < o.(int) && nodeIDs.Len() > 0 {
+			size = o.(int)
 		}
-		p.SetServiceofferingid(serviceOfferingID)
-		p.SetSize(int64(d.Get("size").(int)))
-		_, err := cs.Kubernetes.ScaleKubernetesCluster(p)
-		if err != nil {
+
+		if d.HasChange("service_offering") || size != o.(int) {
+			p := cs.Kubernetes.NewScaleKubernetesClusterParams(d.Id())
+			p.SetSize(int64(size))
+
+			// Only pass an unchanged service offering when not using node
+			// pools, as it would override the service offering of the pools
+			if _, ok := d.GetOk("node_pool"); !ok || d.HasChange("service_offering") {
+				serviceOfferingID, e := retrieveID(cs, "service_offering", d.Get("service_offering").(string))
+				if e != nil {
+					return e.Error()
+				}
+				p.SetServiceofferingid(serviceOfferingID)
+			}
+			_, err := cs.Kubernetes.ScaleKubernetesCluster(p)
+			if err != nil {
+				return fmt.Errorf(
+					"Error Scaling Kubernetes Cluster %s: %s", d.Id(), err)
+			}
+		}
+
+		if size != n.(int) {
+			var ids []string
+			for _, id := range nodeIDs.List() {
+				ids = append(ids, id.(string))
+			}
+
+			log.Printf("[DEBUG] Removing node(s) %s from Kubernetes Cluster %s", strings.Join(ids, ", "), d.Id())
+			p := cs.Kubernetes.NewScaleKubernetesClusterParams(d.Id())
+			p.SetNodeids(ids)
+			_, err := cs.Kubernetes.ScaleKubernetesCluster(p)
+			if err != nil {
+				return fmt.Errorf(
+					"Error Removing nodes from Kubernetes Cluster %s: %s", d.Id(), err)
+			}
+		}
+	}
+
+	if d.HasChange("node_pool") {
+		if err := scaleKubernetesNodePools(cs, d); err != nil {
 			return fmt.Errorf(
-				"Error Scaling Kubernetes Cluster %s: %s", d.Id(), err)
+				"Error Scaling the node pools of Kubernetes Cluster %s: %s", d.Id(), err)
 		}
 	}
 
@@ -290,6 +401,17 @@ func resourceCloudStackKubernetesClusterUpdate(d *schema.ResourceData, meta inte
 		p := cs.Kubernetes.NewUpgradeKubernetesClusterParams(d.Id(), kubernetesVersionID)
 		_, err := cs.Kubernetes.UpgradeKubernetesCluster(p)
 		if err != nil {
+			// Report what the failed upgrade left behind, as CloudStack keeps
+			// the cluster at its previous version when the upgrade fails
+			cluster, _, cerr := cs.Kubernetes.GetKubernetesClusterByID(
+				d.Id(),
+				cloudstack.WithProject(d.Get("project").(string)),
+			)
+			if cerr == nil {
+				return fmt.Errorf(
+					"Error Upgrading Kubernetes Cluster %s to %s: %s (the cluster is in state %s and runs version %s)",
+					d.Id(), d.Get("kubernetes_version").(string), err, cluster.State, cluster.Kubernetesversionname)
+			}
 			return fmt.Errorf(
 				"Error Upgrading Kubernetes Cluster %s: %s", d.Id(), err)
 		}
@@ -320,6 +442,74 @@ func resourceCloudStackKubernetesClusterUpdate(d *schema.ResourceData, meta inte
 	return resourceCloudStackKubernetesClusterRead(d, meta)
 }
 
+func resourceCloudStackKubernetesClusterCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if err := verifyKubernetesNodePools(d.Get("node_pool").([]interface{})); err != nil {
+		return err
+	}
+
+	if d.Id() != "" && d.HasChange("node_pool") {
+		o, n := d.GetChange("node_pool")
+		if err := verifyKubernetesEtcdResize(o.([]interface{}), n.([]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if !d.NewValueKnown("kubernetes_version") {
+		return nil
+	}
+
+	// Only upgrades to a newer version are supported
+	if d.Id() != "" && d.HasChange("kubernetes_version") {
+		o, n := d.GetChange("kubernetes_version")
+		to, err := retrieveKubernetesVersion(cs, n.(string))
+		if err != nil {
+			return err
+		}
+
+		// The current version can be removed once the cluster is deployed,
+		// so fall back to the version reported by the cluster
+		from, err := retrieveKubernetesVersion(cs, o.(string))
+		if err != nil {
+			from, err = reportedKubernetesVersion(cs, d)
+			if err != nil {
+				return err
+			}
+		}
+		if from != nil {
+			if err := verifyKubernetesUpgrade(from, to); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Multiple control nodes require a version that supports HA
+	if d.HasChange("control_nodes_size") && d.Get("control_nodes_size").(int) > 1 {
+		v, err := retrieveKubernetesVersion(cs, d.Get("kubernetes_version").(string))
+		if err != nil {
+			return err
+		}
+		if !v.Supportsha {
+			return fmt.Errorf(
+				"Kubernetes version %s does not support multiple control nodes", v.Name)
+		}
+	}
+
+	// Specific nodes can only be removed when scaling in
+	if d.Id() != "" && d.HasChange("size") {
+		o, n := d.GetChange("size")
+		nodeIDs := d.Get("scale_in_node_ids").(*schema.Set)
+		if n.(int) < o.(int) && nodeIDs.Len() > 0 && nodeIDs.Len() != o.(int)-n.(int) {
+			return fmt.Errorf(
+				"Scaling in from %d to %d nodes requires exactly %d scale_in_node_ids, got %d",
+				o.(int), n.(int), o.(int)-n.(int), nodeIDs.Len())
+		}
+	}
+
+	return nil
+}
+
 func resourceCloudStackKubernetesClusterDelete(d *schema.ResourceData, meta interface{}) error {
 	cs := meta.(*cloudstack.CloudStackClient)
 
@@ -341,3 +531,89 @@ func resourceCloudStackKubernetesClusterDelete(d *schema.ResourceData, meta inte
 
 	return nil
 }
+
+func retrieveKubernetesVersion(cs *cloudstack.CloudStackClient, value string) (*cloudstack.KubernetesSupportedVersion, error) {
+	id, e := retrieveID(cs, "kubernetes_version", value)
+	if e != nil {
+		return nil, e.Error()
+	}
+
+	v, _, err := cs.Kubernetes.GetKubernetesSupportedVersionByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving Kubernetes version %s: %s", value, err)
+	}
+
+	return v, nil
+}
+
+// reportedKubernetesVersion returns the Kubernetes version reported by the
+// cluster, or nil if the version can't be derived from its name.
+func reportedKubernetesVersion(cs *cloudstack.CloudStackClient, d *schema.ResourceDiff) (*cloudstack.KubernetesSupportedVersion, error) {
+	cluster, _, err := cs.Kubernetes.GetKubernetesClusterByID(
+		d.Id(),
+		cloudstack.WithProject(d.Get("project").(string)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving Kubernetes Cluster %s: %s", d.Id(), err)
+	}
+
+	v := kubernetesVersionFromName(cluster.Kubernetesversionname)
+	if v == nil {
+		log.Printf(
+			"[WARN] Unable to verify the upgrade of Kubernetes Cluster %s: version %s no longer exists",
+			d.Id(), cluster.Kubernetesversionname)
+	}
+
+	return v, nil
+}
+
+var kubernetesSemanticVersion = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+// kubernetesVersionFromName derives a Kubernetes version from the name of a
+// version that no longer exists, which usually contains its semantic version.
+func kubernetesVersionFromName(name string) *cloudstack.KubernetesSupportedVersion {
+	semver := kubernetesSemanticVersion.FindString(name)
+	if semver == "" {
+		return nil
+	}
+
+	return &cloudstack.KubernetesSupportedVersion{
+		Name:            name,
+		Semanticversion: semver,
+		State:           "Enabled",
+	}
+}
+
+// verifyKubernetesUpgrade returns an error if a cluster cannot be upgraded
+// between the given versions. CloudStack only supports upgrading to the next
+// minor or patch release of the same major version.
+func verifyKubernetesUpgrade(from, to *cloudstack.KubernetesSupportedVersion) error {
+	fv, err := version.NewVersion(from.Semanticversion)
+	if err != nil {
+		return fmt.Errorf("Invalid Kubernetes version %s: %s", from.Semanticversion, err)
+	}
+	tv, err := version.NewVersion(to.Semanticversion)
+	if err != nil {
+		return fmt.Errorf("Invalid Kubernetes version %s: %s", to.Semanticversion, err)
+	}
+
+	if !tv.GreaterThan(fv) {
+		return fmt.Errorf(
+			"Kubernetes clusters can only be upgraded to a newer version: %s (%s) is not newer than %s (%s)",
+			to.Name, to.Semanticversion, from.Name, from.Semanticversion)
+	}
+
+	fs, ts := fv.Segments(), tv.Segments()
+	if fs[0] != ts[0] || ts[1]-fs[1] > 1 {
+		return fmt.Errorf(
+			"Kubernetes clusters can only be upgraded to the next minor or patch release: "+
+				"cannot upgrade from %s to %s", from.Semanticversion, to.Semanticversion)
+	}
+
+	if to.State != "Enabled" {
+		return fmt.Errorf(
+			"Kubernetes version %s is %s and cannot be used to upgrade clusters", to.Name, to.State)
+	}
+
+	return nil
+}
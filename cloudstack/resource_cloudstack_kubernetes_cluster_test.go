@@ -0,0 +1,85 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+)
+
+func TestVerifyKubernetesUpgrade(t *testing.T) {
+	cases := []struct {
+		desc     string
+		from, to string
+		state    string
+		valid    bool
+	}{
+		{"patch release", "1.24.0", "1.24.3", "Enabled", true},
+		{"next minor release", "1.24.3", "1.25.0", "Enabled", true},
+		{"same version", "1.24.3", "1.24.3", "Enabled", false},
+		{"downgrade to older minor", "1.25.0", "1.24.3", "Enabled", false},
+		{"downgrade to older patch", "1.24.3", "1.24.1", "Enabled", false},
+		{"skipped minor release", "1.24.3", "1.26.0", "Enabled", false},
+		{"skipped minor releases", "1.24.3", "1.27.0", "Enabled", false},
+		{"major version change", "1.24.3", "2.0.0", "Enabled", false},
+		{"major version change to same minor", "1.24.3", "2.25.0", "Enabled", false},
+		{"disabled minor release", "1.24.3", "1.25.0", "Disabled", false},
+		{"disabled patch release", "1.24.3", "1.24.5", "Disabled", false},
+	}
+
+	for _, c := range cases {
+		from := &cloudstack.KubernetesSupportedVersion{Name: c.from, Semanticversion: c.from, State: "Enabled"}
+		to := &cloudstack.KubernetesSupportedVersion{Name: c.to, Semanticversion: c.to, State: c.state}
+
+		err := verifyKubernetesUpgrade(from, to)
+		if c.valid && err != nil {
+			t.Fatalf("%s: expected upgrade from %s to %s (%s) to be valid: %s", c.desc, c.from, c.to, c.state, err)
+		}
+		if !c.valid && err == nil {
+			t.Fatalf("%s: expected upgrade from %s to %s (%s) to be invalid", c.desc, c.from, c.to, c.state)
+		}
+	}
+}
+
+func TestKubernetesVersionFromName(t *testing.T) {
+	cases := []struct {
+		name   string
+		semver string
+	}{
+		{"v1.24.3", "1.24.3"},
+		{"1.28.4-Kubernetes-Binaries-ISO", "1.28.4"},
+		{"kubernetes-1.27.1-custom", "1.27.1"},
+		{"latest", ""},
+	}
+
+	for _, c := range cases {
+		v := kubernetesVersionFromName(c.name)
+		if c.semver == "" {
+			if v != nil {
+				t.Fatalf("expected no version for %s, got %s", c.name, v.Semanticversion)
+			}
+			continue
+		}
+		if v == nil || v.Semanticversion != c.semver {
+			t.Fatalf("expected version %s for %s, got %#v", c.semver, c.name, v)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceCloudStackKubernetesVersion() *schema.Resource {
@@ -85,10 +86,29 @@ func resourceCloudStackKubernetesVersion() *schema.Resource {
 			},
 
 			"state": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Enabled", "Disabled"}, false),
+			},
+
+			"iso_id": {
 				Type:     schema.TypeString,
-				Optional: true,
 				Computed: true,
 			},
+
+			"is_ready": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"is_ready_timeout": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  300,
+			},
+
+			"zone_status": imageZoneStatusSchema(),
 		},
 	}
 }
@@ -96,13 +116,6 @@ func resourceCloudStackKubernetesVersion() *schema.Resource {
 func resourceCloudStackKubernetesVersionCreate(d *schema.ResourceData, meta interface{}) error {
 	cs := meta.(*cloudstack.CloudStackClient)
 
-	// State is always Enabled when created
-	if state, ok := d.GetOk("state"); ok {
-		if state.(string) != "Enabled" {
-			return fmt.Errorf("State must be 'Enabled' when first adding an ISO")
-		}
-	}
-
 	semanticVersion := d.Get("semantic_version").(string)
 	url := d.Get("url").(string)
 	minCpu := d.Get("min_cpu").(int)
@@ -115,7 +128,7 @@ func resourceCloudStackKubernetesVersionCreate(d *schema.ResourceData, meta inte
 		p.SetName(name.(string))
 	}
 	if checksum, ok := d.GetOk("checksum"); ok {
-		p.SetName(checksum.(string))
+		p.SetChecksum(checksum.(string))
 	}
 	if zone, ok := d.GetOk("zone"); ok {
 		zoneID, e := retrieveID(cs, "zone", zone.(string))
@@ -133,7 +146,19 @@ func resourceCloudStackKubernetesVersionCreate(d *schema.ResourceData, meta inte
 
 	log.Printf("[DEBUG] Kubernetes Version %s successfully created", semanticVersion)
 	d.SetId(r.Id)
-	return resourceCloudStackKubernetesVersionRead(d, meta)
+
+	// A version is always Enabled when created
+	if state, ok := d.GetOk("state"); ok && state.(string) != "Enabled" {
+		p := cs.Kubernetes.NewUpdateKubernetesSupportedVersionParams(d.Id(), state.(string))
+		_, err := cs.Kubernetes.UpdateKubernetesSupportedVersion(p)
+		if err != nil {
+			return fmt.Errorf(
+				"Error Updating Kubernetes Version %s: %s", d.Id(), err)
+		}
+	}
+
+	// Wait until the ISO is ready to use, or timeout with an error...
+	return waitForImageReady(d, meta, "Kubernetes version ISO", resourceCloudStackKubernetesVersionRead)
 }
 
 func resourceCloudStackKubernetesVersionRead(d *schema.ResourceData, meta interface{}) error {
@@ -162,8 +187,31 @@ func resourceCloudStackKubernetesVersionRead(d *schema.ResourceData, meta interf
 	d.Set("min_cpu", version.Mincpunumber)
 	d.Set("min_memory", version.Minmemory)
 	d.Set("state", version.State)
+	d.Set("iso_id", version.Isoid)
 
 	setValueOrID(d, "zone", version.Zonename, version.Zoneid)
+
+	// Get the ISO details for all zones
+	p := cs.ISO.NewListIsosParams()
+	p.SetId(version.Isoid)
+	p.SetIsofilter("all")
+
+	r, err := cs.ISO.ListIsos(p)
+	if err != nil {
+		return fmt.Errorf("Error retrieving the ISO of Kubernetes Version %s: %s", d.Id(), err)
+	}
+
+	zones := make([]imageZone, 0, len(r.Isos))
+	for _, i := range r.Isos {
+		zones = append(zones, imageZone{i.Zoneid, i.Zonename, i.Isready, i.Status})
+	}
+
+	var zoneids []string
+	if version.Zoneid != "" {
+		zoneids = append(zoneids, version.Zoneid)
+	}
+	d.Set("is_ready", setImageZoneStatus(d, zones, zoneids))
+
 	return nil
 }
 
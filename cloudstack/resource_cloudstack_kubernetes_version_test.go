@@ -43,6 +43,8 @@ func TestAccCloudStackKubernetesVersion_basic(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckCloudStackKubernetesVersionExists("cloudstack_kubernetes_version.foo", &version),
 					testAccCheckCloudStackKubernetesVersionAttributes(&version),
+					resource.TestCheckResourceAttr(
+						"cloudstack_kubernetes_version.foo", "is_ready", "true"),
 				),
 			},
 		},
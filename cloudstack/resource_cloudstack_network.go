@@ -20,14 +20,18 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const none = "none"
@@ -56,6 +60,9 @@ func resourceCloudStackNetwork() *schema.Resource {
 		Read:   resourceCloudStackNetworkRead,
 		Update: resourceCloudStackNetworkUpdate,
 		Delete: resourceCloudStackNetworkDelete,
+
+		CustomizeDiff: resourceCloudStackNetworkCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: importStatePassthrough,
 		},
@@ -74,7 +81,7 @@ func resourceCloudStackNetwork() *schema.Resource {
 
 			"cidr": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 				ForceNew: true,
 			},
 
@@ -99,6 +106,32 @@ func resourceCloudStackNetwork() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"ip6_cidr": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"ip6_gateway": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"start_ipv6": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"end_ipv6": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
 			"network_domain": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -110,12 +143,54 @@ func resourceCloudStackNetwork() *schema.Resource {
 				Required: true,
 			},
 
+			"change_cidr": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"forced": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"vlan": {
 				Type:     schema.TypeInt,
 				Optional: true,
 				ForceNew: true,
 			},
 
+			"bypass_vlan_overlap_check": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"isolated_pvlan": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"isolated_pvlan_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"community",
+					"isolated",
+					"promiscuous",
+				}, false),
+			},
+
+			"physical_network_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
 			"vpc_id": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -147,6 +222,28 @@ func resourceCloudStackNetwork() *schema.Resource {
 				Computed: true,
 			},
 
+			"redundant_router": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+
+			"restart_required": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"restart_trigger": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cleanup": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"zone": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -190,23 +287,48 @@ func resourceCloudStackNetworkCreate(d *schema.ResourceData, meta interface{}) e
 		return err
 	}
 
-	m, err := parseCIDR(d, no.Specifyipranges)
-	if err != nil {
-		return err
-	}
+	// L2 networks don't have any IP config, so only set it if we have a CIDR
+	if _, ok := d.GetOk("cidr"); ok {
+		m, err := parseCIDR(d, no.Specifyipranges)
+		if err != nil {
+			return err
+		}
 
-	// Set the needed IP config
-	p.SetGateway(m["gateway"])
-	p.SetNetmask(m["netmask"])
+		// Set the needed IP config
+		p.SetGateway(m["gateway"])
+		p.SetNetmask(m["netmask"])
 
-	// Only set the start IP if we have one
-	if startip, ok := m["startip"]; ok {
-		p.SetStartip(startip)
+		// Only set the start IP if we have one
+		if startip, ok := m["startip"]; ok {
+			p.SetStartip(startip)
+		}
+
+		// Only set the end IP if we have one
+		if endip, ok := m["endip"]; ok {
+			p.SetEndip(endip)
+		}
+	} else if no.Guestiptype != "L2" {
+		return fmt.Errorf(
+			"Parameter cidr is required when using a network offering with guest IP type %s", no.Guestiptype)
 	}
 
-	// Only set the end IP if we have one
-	if endip, ok := m["endip"]; ok {
-		p.SetEndip(endip)
+	// Set the IPv6 config if we have one
+	if ip6cidr, ok := d.GetOk("ip6_cidr"); ok {
+		m, err := parseCIDR6(d, ip6cidr.(string))
+		if err != nil {
+			return err
+		}
+
+		p.SetIp6cidr(ip6cidr.(string))
+		p.SetIp6gateway(m["ip6_gateway"])
+
+		// Only set the IPv6 range if we have one
+		if startipv6, ok := m["start_ipv6"]; ok {
+			p.SetStartipv6(startipv6)
+		}
+		if endipv6, ok := m["end_ipv6"]; ok {
+			p.SetEndipv6(endipv6)
+		}
 	}
 
 	// Set the network domain if we have one
@@ -218,6 +340,23 @@ func resourceCloudStackNetworkCreate(d *schema.ResourceData, meta interface{}) e
 		p.SetVlan(strconv.Itoa(vlan.(int)))
 	}
 
+	if d.Get("bypass_vlan_overlap_check").(bool) {
+		p.SetBypassvlanoverlapcheck(true)
+	}
+
+	// Set the private VLAN config if we have one
+	if pvlan, ok := d.GetOk("isolated_pvlan"); ok {
+		p.SetIsolatedpvlan(pvlan.(string))
+	}
+	if pvlantype, ok := d.GetOk("isolated_pvlan_type"); ok {
+		p.SetIsolatedpvlantype(pvlantype.(string))
+	}
+
+	// Set the physical network if we have one
+	if physicalnetworkid, ok := d.GetOk("physical_network_id"); ok {
+		p.SetPhysicalnetworkid(physicalnetworkid.(string))
+	}
+
 	// Check is this network needs to be created in a VPC
 	if vpcid, ok := d.GetOk("vpc_id"); ok {
 		// Set the vpc id
@@ -277,6 +416,13 @@ func resourceCloudStackNetworkCreate(d *schema.ResourceData, meta interface{}) e
 		// Set the additional partial
 	}
 
+	// Make the network routers redundant if the offering didn't already do so
+	if d.Get("redundant_router").(bool) && !r.Redundantrouter {
+		if err := restartNetwork(d, meta); err != nil {
+			return err
+		}
+	}
+
 	return resourceCloudStackNetworkRead(d, meta)
 }
 
@@ -303,8 +449,13 @@ func resourceCloudStackNetworkRead(d *schema.ResourceData, meta interface{}) err
 	d.Set("display_text", n.Displaytext)
 	d.Set("cidr", n.Cidr)
 	d.Set("gateway", n.Gateway)
+	d.Set("ip6_cidr", n.Ip6cidr)
+	d.Set("ip6_gateway", n.Ip6gateway)
 	d.Set("network_domain", n.Networkdomain)
 	d.Set("vpc_id", n.Vpcid)
+	d.Set("physical_network_id", n.Physicalnetworkid)
+	d.Set("redundant_router", n.Redundantrouter)
+	d.Set("restart_required", n.Restartrequired)
 
 	if n.Aclid == "" {
 		n.Aclid = none
@@ -385,6 +536,10 @@ func resourceCloudStackNetworkUpdate(d *schema.ResourceData, meta interface{}) e
 		}
 		// Set the new network offering
 		p.SetNetworkofferingid(networkofferingid)
+
+		// Allow changing the CIDR type and forcing the update if requested
+		p.SetChangecidr(d.Get("change_cidr").(bool))
+		p.SetForced(d.Get("forced").(bool))
 	}
 
 	// Update the network
@@ -405,6 +560,13 @@ func resourceCloudStackNetworkUpdate(d *schema.ResourceData, meta interface{}) e
 		}
 	}
 
+	// Check if the network needs to be restarted or its routers made redundant
+	if d.HasChange("restart_trigger") || d.HasChange("redundant_router") {
+		if err := restartNetwork(d, meta); err != nil {
+			return err
+		}
+	}
+
 	// Update tags if they have changed
 	if d.HasChange("tags") {
 		if err := updateTags(cs, d, "Network"); err != nil {
@@ -415,6 +577,118 @@ func resourceCloudStackNetworkUpdate(d *schema.ResourceData, meta interface{}) e
 	return resourceCloudStackNetworkRead(d, meta)
 }
 
+func restartNetwork(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Create a new parameter struct
+	p := cs.Network.NewRestartNetworkParams(d.Id())
+
+	// Set the cleanup option
+	p.SetCleanup(d.Get("cleanup").(bool))
+
+	// Make the network routers redundant if requested
+	if d.HasChange("redundant_router") && d.Get("redundant_router").(bool) {
+		p.SetMakeredundant(true)
+	}
+
+	log.Printf("[DEBUG] Restarting network %s", d.Get("name").(string))
+
+	// Restart the network
+	_, err := cs.Network.RestartNetwork(p)
+	if err != nil {
+		return fmt.Errorf(
+			"Error restarting network %s: %s", d.Get("name").(string), err)
+	}
+
+	return nil
+}
+
+func resourceCloudStackNetworkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// Nothing to check when the network is about to be created
+	if d.Id() == "" {
+		return nil
+	}
+
+	cs := meta.(*cloudstack.CloudStackClient)
+	name := d.Get("name").(string)
+
+	// Redundant routers can only be removed by changing the network offering
+	if d.HasChange("redundant_router") && !d.HasChange("network_offering") {
+		o, n := d.GetChange("redundant_router")
+		if o.(bool) && !n.(bool) {
+			return fmt.Errorf(
+				"Redundant routers of network %s can only be removed by changing "+
+					"to a network offering without redundant router support", name)
+		}
+	}
+
+	if d.HasChange("network_offering") && d.NewValueKnown("network_offering") {
+		o, n := d.GetChange("network_offering")
+		recreate, err := networkOfferingChangeRecreatesRouter(cs, o.(string), n.(string))
+		if err != nil {
+			return err
+		}
+		if recreate {
+			log.Printf(
+				"[WARN] Changing the network offering of network %s from %s to %s "+
+					"will recreate its virtual router, as the offerings use different "+
+					"services or providers", name, o.(string), n.(string))
+		}
+	}
+
+	if d.HasChange("restart_trigger") || d.HasChange("redundant_router") {
+		if d.Get("cleanup").(bool) {
+			log.Printf(
+				"[WARN] Restarting network %s with cleanup enabled will recreate "+
+					"its virtual router", name)
+		}
+	}
+
+	return nil
+}
+
+// networkOfferingChangeRecreatesRouter returns true if the services or
+// providers of the old and new network offering differ.
+func networkOfferingChangeRecreatesRouter(cs *cloudstack.CloudStackClient, from, to string) (bool, error) {
+	oldid, e := retrieveID(cs, "network_offering", from)
+	if e != nil {
+		// The old offering may have been removed, so its services are unknown
+		log.Printf("[DEBUG] Unable to retrieve network offering %s: %s", from, e.Error())
+		return false, nil
+	}
+	o, _, err := cs.NetworkOffering.GetNetworkOfferingByID(oldid)
+	if err != nil {
+		log.Printf("[DEBUG] Unable to retrieve network offering %s: %s", from, err)
+		return false, nil
+	}
+
+	newid, e := retrieveID(cs, "network_offering", to)
+	if e != nil {
+		return false, e.Error()
+	}
+	n, _, err := cs.NetworkOffering.GetNetworkOfferingByID(newid)
+	if err != nil {
+		return false, err
+	}
+
+	return !reflect.DeepEqual(networkOfferingServices(o), networkOfferingServices(n)), nil
+}
+
+// networkOfferingServices returns the sorted providers of each service of the
+// network offering.
+func networkOfferingServices(o *cloudstack.NetworkOffering) map[string][]string {
+	services := make(map[string][]string, len(o.Service))
+	for _, service := range o.Service {
+		providers := make([]string, 0, len(service.Provider))
+		for _, provider := range service.Provider {
+			providers = append(providers, provider.Name)
+		}
+		sort.Strings(providers)
+		services[service.Name] = providers
+	}
+	return services
+}
+
 func resourceCloudStackNetworkDelete(d *schema.ResourceData, meta interface{}) error {
 	cs := meta.(*cloudstack.CloudStackClient)
 
@@ -471,3 +745,32 @@ func parseCIDR(d *schema.ResourceData, specifyiprange bool) (map[string]string,
 
 	return m, nil
 }
+
+func parseCIDR6(d *schema.ResourceData, cidr string) (map[string]string, error) {
+	m := make(map[string]string, 3)
+
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil || ip.To4() != nil {
+		return nil, fmt.Errorf("Unable to parse ip6_cidr %s as an IPv6 CIDR", cidr)
+	}
+
+	if gateway, ok := d.GetOk("ip6_gateway"); ok {
+		m["ip6_gateway"] = gateway.(string)
+	} else {
+		// Default to the first address of the subnet, like we do for IPv4
+		gw := make(net.IP, len(ipnet.IP))
+		copy(gw, ipnet.IP)
+		gw[len(gw)-1]++
+		m["ip6_gateway"] = gw.String()
+	}
+
+	if startipv6, ok := d.GetOk("start_ipv6"); ok {
+		m["start_ipv6"] = startipv6.(string)
+	}
+
+	if endipv6, ok := d.GetOk("end_ipv6"); ok {
+		m["end_ipv6"] = endipv6.(string)
+	}
+
+	return m, nil
+}
@@ -20,8 +20,11 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,6 +33,7 @@ import (
 	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceCloudStackNetworkACLRule() *schema.Resource {
@@ -39,6 +43,12 @@ func resourceCloudStackNetworkACLRule() *schema.Resource {
 		Update: resourceCloudStackNetworkACLRuleUpdate,
 		Delete: resourceCloudStackNetworkACLRuleDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceCloudStackNetworkACLRuleImport,
+		},
+
+		CustomizeDiff: resourceCloudStackNetworkACLRuleCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"acl_id": {
 				Type:     schema.TypeString,
@@ -57,6 +67,17 @@ func resourceCloudStackNetworkACLRule() *schema.Resource {
 				Optional: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
+						"rule_number": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
 						"action": {
 							Type:     schema.TypeString,
 							Optional: true,
@@ -70,9 +91,14 @@ func resourceCloudStackNetworkACLRule() *schema.Resource {
 							Set:      schema.HashString,
 						},
 
+						"service": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
 						"protocol": {
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
 						},
 
 						"icmp_type": {
@@ -114,6 +140,12 @@ func resourceCloudStackNetworkACLRule() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"atomic_replace": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"parallelism": {
 				Type:     schema.TypeInt,
 				Optional: true,
@@ -137,7 +169,7 @@ func resourceCloudStackNetworkACLRuleCreate(d *schema.ResourceData, meta interfa
 		// Create an empty rule set to hold all newly created rules
 		rules := resourceCloudStackNetworkACLRule().Schema["rule"].ZeroValue().(*schema.Set)
 
-		err := createNetworkACLRules(d, meta, rules, nrs)
+		err := createNetworkACLRules(d, meta, d.Id(), rules, nrs)
 
 		// We need to update this first to preserve the correct state
 		d.Set("rule", rules)
@@ -150,7 +182,7 @@ func resourceCloudStackNetworkACLRuleCreate(d *schema.ResourceData, meta interfa
 	return resourceCloudStackNetworkACLRuleRead(d, meta)
 }
 
-func createNetworkACLRules(d *schema.ResourceData, meta interface{}, rules *schema.Set, nrs *schema.Set) error {
+func createNetworkACLRules(d *schema.ResourceData, meta interface{}, aclid string, rules *schema.Set, nrs *schema.Set) error {
 	var errs *multierror.Error
 
 	var wg sync.WaitGroup
@@ -165,12 +197,15 @@ func createNetworkACLRules(d *schema.ResourceData, meta interface{}, rules *sche
 			defer wg.Done()
 			sem <- struct{}{}
 
+			// Expand the referenced service, if any
+			rule = withRuleService(rule)
+
 			// Create a single rule
-			err := createNetworkACLRule(d, meta, rule)
+			err := createNetworkACLRule(d, meta, aclid, rule)
 
 			// If we have at least one UUID, we need to save the rule
 			if len(rule["uuids"].(map[string]interface{})) > 0 {
-				rules.Add(rule)
+				rules.Add(collapseRuleService(rule))
 			}
 
 			if err != nil {
@@ -186,7 +221,7 @@ func createNetworkACLRules(d *schema.ResourceData, meta interface{}, rules *sche
 	return errs.ErrorOrNil()
 }
 
-func createNetworkACLRule(d *schema.ResourceData, meta interface{}, rule map[string]interface{}) error {
+func createNetworkACLRule(d *schema.ResourceData, meta interface{}, aclid string, rule map[string]interface{}) error {
 	cs := meta.(*cloudstack.CloudStackClient)
 	uuids := rule["uuids"].(map[string]interface{})
 
@@ -199,7 +234,7 @@ func createNetworkACLRule(d *schema.ResourceData, meta interface{}, rule map[str
 	p := cs.NetworkACL.NewCreateNetworkACLParams(rule["protocol"].(string))
 
 	// Set the acl ID
-	p.SetAclid(d.Id())
+	p.SetAclid(aclid)
 
 	// Set the action
 	p.SetAction(rule["action"].(string))
@@ -214,11 +249,23 @@ func createNetworkACLRule(d *schema.ResourceData, meta interface{}, rule map[str
 	// Set the traffic type
 	p.SetTraffictype(rule["traffic_type"].(string))
 
+	// Set the description
+	if desc := rule["description"].(string); desc != "" {
+		p.SetReason(desc)
+	}
+
+	// Get the rule numbers to use, if any
+	numbers := networkACLRuleNumbers(rule)
+
 	// If the protocol is ICMP set the needed ICMP parameters
-	if rule["protocol"].(string) == "icmp" {
+	if _, ok := uuids["icmp"]; !ok && rule["protocol"].(string) == "icmp" {
 		p.SetIcmptype(rule["icmp_type"].(int))
 		p.SetIcmpcode(rule["icmp_code"].(int))
 
+		if number, ok := numbers["icmp"]; ok {
+			p.SetNumber(number)
+		}
+
 		r, err := Retry(4, retryableACLCreationFunc(cs, p))
 		if err != nil {
 			return err
@@ -229,7 +276,11 @@ func createNetworkACLRule(d *schema.ResourceData, meta interface{}, rule map[str
 	}
 
 	// If the protocol is ALL set the needed parameters
-	if rule["protocol"].(string) == "all" {
+	if _, ok := uuids["all"]; !ok && rule["protocol"].(string) == "all" {
+		if number, ok := numbers["all"]; ok {
+			p.SetNumber(number)
+		}
+
 		r, err := Retry(4, retryableACLCreationFunc(cs, p))
 		if err != nil {
 			return err
@@ -271,6 +322,10 @@ func createNetworkACLRule(d *schema.ResourceData, meta interface{}, rule map[str
 				p.SetStartport(startPort)
 				p.SetEndport(endPort)
 
+				if number, ok := numbers[port.(string)]; ok {
+					p.SetNumber(number)
+				}
+
 				r, err := Retry(4, retryableACLCreationFunc(cs, p))
 				if err != nil {
 					return err
@@ -329,7 +384,8 @@ func resourceCloudStackNetworkACLRuleRead(d *schema.ResourceData, meta interface
 	// Read all rules that are configured
 	if rs := d.Get("rule").(*schema.Set); rs.Len() > 0 {
 		for _, rule := range rs.List() {
-			rule := rule.(map[string]interface{})
+			// Expand the referenced service, if any, so its ports can be read
+			rule := withRuleService(rule.(map[string]interface{}))
 			uuids := rule["uuids"].(map[string]interface{})
 
 			if rule["protocol"].(string) == "icmp" {
@@ -361,7 +417,14 @@ func resourceCloudStackNetworkACLRuleRead(d *schema.ResourceData, meta interface
 				rule["icmp_code"] = r.Icmpcode
 				rule["traffic_type"] = strings.ToLower(r.Traffictype)
 				rule["cidr_list"] = cidrs
-				rules.Add(rule)
+				rule["description"] = r.Reason
+
+				// Only track the number if the rule is ordered explicitly
+				if rule["rule_number"].(int) > 0 {
+					rule["rule_number"] = r.Number
+				}
+
+				rules.Add(collapseRuleService(rule))
 			}
 
 			if rule["protocol"].(string) == "all" {
@@ -391,7 +454,14 @@ func resourceCloudStackNetworkACLRuleRead(d *schema.ResourceData, meta interface
 				rule["protocol"] = r.Protocol
 				rule["traffic_type"] = strings.ToLower(r.Traffictype)
 				rule["cidr_list"] = cidrs
-				rules.Add(rule)
+				rule["description"] = r.Reason
+
+				// Only track the number if the rule is ordered explicitly
+				if rule["rule_number"].(int) > 0 {
+					rule["rule_number"] = r.Number
+				}
+
+				rules.Add(collapseRuleService(rule))
 			}
 
 			// If protocol is tcp or udp, loop through all ports
@@ -401,6 +471,10 @@ func resourceCloudStackNetworkACLRuleRead(d *schema.ResourceData, meta interface
 					// Create an empty schema.Set to hold all ports
 					ports := &schema.Set{F: schema.HashString}
 
+					// Get the numbers of the ports within the ordered rule
+					numbers := networkACLRuleNumbers(rule)
+					base := rule["rule_number"].(int)
+
 					// Loop through all ports and retrieve their info
 					for _, port := range ps.List() {
 						id, ok := uuids[port.(string)]
@@ -429,13 +503,20 @@ func resourceCloudStackNetworkACLRuleRead(d *schema.ResourceData, meta interface
 						rule["protocol"] = r.Protocol
 						rule["traffic_type"] = strings.ToLower(r.Traffictype)
 						rule["cidr_list"] = cidrs
+						rule["description"] = r.Reason
+
+						// Only track the number if the rule is ordered explicitly
+						if number, ok := numbers[port.(string)]; ok {
+							rule["rule_number"] = r.Number - (number - base)
+						}
+
 						ports.Add(port)
 					}
 
 					// If there is at least one port found, add this rule to the rules set
 					if ports.Len() > 0 {
 						rule["ports"] = ports
-						rules.Add(rule)
+						rules.Add(collapseRuleService(rule))
 					}
 				}
 			}
@@ -480,6 +561,14 @@ func resourceCloudStackNetworkACLRuleUpdate(d *schema.ResourceData, meta interfa
 
 	// Check if the rule set as a whole has changed
 	if d.HasChange("rule") {
+		if d.Get("atomic_replace").(bool) {
+			if err := replaceNetworkACLRules(d, meta); err != nil {
+				return err
+			}
+
+			return resourceCloudStackNetworkACLRuleRead(d, meta)
+		}
+
 		o, n := d.GetChange("rule")
 		ors := o.(*schema.Set).Difference(n.(*schema.Set))
 		nrs := n.(*schema.Set).Difference(o.(*schema.Set))
@@ -490,9 +579,23 @@ func resourceCloudStackNetworkACLRuleUpdate(d *schema.ResourceData, meta interfa
 		// set to make sure we end up in a consistent state
 		rules := o.(*schema.Set).Intersection(n.(*schema.Set))
 
+		// Pair changed rules that only differ in their ports, CIDR list, number
+		// or description, so only the ACL items that actually changed are touched
+		var changed []map[string]interface{}
+		for _, pair := range pairRules(ors, nrs, networkACLRuleIdentity, ruleUnits) {
+			if networkACLRuleItemsChanged(pair[0], pair[1]) {
+				changed = append(changed, copyNetworkACLRule(pair[1]))
+			}
+		}
+
+		// Make sure the numbers of the new and changed rules are available
+		if err := freeNetworkACLRuleNumbers(d, meta, nrs); err != nil {
+			return err
+		}
+
 		// First loop through all the new rules and create (before destroy) them
 		if nrs.Len() > 0 {
-			err := createNetworkACLRules(d, meta, rules, nrs)
+			err := createNetworkACLRules(d, meta, d.Id(), rules, nrs)
 
 			// We need to update this first to preserve the correct state
 			d.Set("rule", rules)
@@ -513,6 +616,13 @@ func resourceCloudStackNetworkACLRuleUpdate(d *schema.ResourceData, meta interfa
 				return err
 			}
 		}
+
+		// Finally update the existing ACL items of the changed rules
+		if len(changed) > 0 {
+			if err := updateNetworkACLRules(d, meta, changed); err != nil {
+				return err
+			}
+		}
 	}
 
 	return resourceCloudStackNetworkACLRuleRead(d, meta)
@@ -610,6 +720,609 @@ func deleteNetworkACLRule(d *schema.ResourceData, meta interface{}, rule map[str
 	return nil
 }
 
+// replaceNetworkACLRules applies a changed rule set without exposing a partially
+// updated ACL to the tiers using it. The new rules are first created in a fresh
+// ACL list, which then replaces the ACL on all tiers and private gateways. While
+// the ACL is not in use its rules are recreated, after which it replaces the
+// fresh ACL list again and the fresh list is deleted.
+func replaceNetworkACLRules(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	o, n := d.GetChange("rule")
+
+	acl, _, err := cs.NetworkACL.GetNetworkACLListByID(
+		d.Id(),
+		cloudstack.WithProject(d.Get("project").(string)),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Find any fresh ACL lists left behind by a previous failed replacement,
+	// so the tiers still using them are swapped back as well
+	description := fmt.Sprintf("Replacement of network ACL list %s", d.Id())
+
+	lp := cs.NetworkACL.NewListNetworkACLListsParams()
+	lp.SetVpcid(acl.Vpcid)
+	lp.SetListall(true)
+
+	ll, err := cs.NetworkACL.ListNetworkACLLists(lp)
+	if err != nil {
+		return err
+	}
+
+	aclids := []string{d.Id()}
+	var leftovers []string
+	for _, l := range ll.NetworkACLLists {
+		if l.Description == description {
+			aclids = append(aclids, l.Id)
+			leftovers = append(leftovers, l.Id)
+		}
+	}
+
+	// Get the tiers and private gateways using any of these ACL lists
+	networks, gateways, err := listNetworkACLListUsers(d, meta, acl.Vpcid, aclids)
+	if err != nil {
+		return err
+	}
+
+	// Create the fresh ACL list
+	name := fmt.Sprintf("%s-%d", acl.Name, time.Now().Unix())
+	p := cs.NetworkACL.NewCreateNetworkACLListParams(name, acl.Vpcid)
+	p.SetDescription(description)
+
+	r, err := cs.NetworkACL.CreateNetworkACLList(p)
+	if err != nil {
+		return fmt.Errorf("Error creating network ACL list %s: %s", name, err)
+	}
+
+	// Populate the fresh ACL list with the new rules
+	staged := resourceCloudStackNetworkACLRule().Schema["rule"].ZeroValue().(*schema.Set)
+	if err := createNetworkACLRules(d, meta, r.Id, staged, freshNetworkACLRules(n.(*schema.Set))); err != nil {
+		deleteStagedNetworkACLList(cs, r.Id)
+		return fmt.Errorf("Error populating network ACL list %s: %s", name, err)
+	}
+
+	// Swap the fresh ACL list onto all tiers and private gateways
+	if err := replaceNetworkACLList(cs, r.Id, networks, gateways); err != nil {
+		return fmt.Errorf("Error replacing network ACL list %s with %s: %s", d.Id(), r.Id, err)
+	}
+
+	// Create an empty rule set to hold all rules of the ACL
+	rules := resourceCloudStackNetworkACLRule().Schema["rule"].ZeroValue().(*schema.Set)
+
+	// Recreate all rules of the ACL while it is not in use
+	err = deleteNetworkACLRules(d, meta, rules, o.(*schema.Set))
+	if err == nil {
+		err = createNetworkACLRules(d, meta, d.Id(), rules, freshNetworkACLRules(n.(*schema.Set)))
+	}
+
+	// We need to update this first to preserve the correct state
+	d.Set("rule", rules)
+
+	if err != nil {
+		return fmt.Errorf(
+			"Error recreating the rules of network ACL list %s, the tiers and private "+
+				"gateways are still using network ACL list %s: %s", d.Id(), r.Id, err)
+	}
+
+	// Swap the ACL back onto all tiers and private gateways
+	if err := replaceNetworkACLList(cs, d.Id(), networks, gateways); err != nil {
+		return fmt.Errorf(
+			"Error replacing network ACL list %s with %s: %s", r.Id, d.Id(), err)
+	}
+
+	for _, id := range append(leftovers, r.Id) {
+		deleteStagedNetworkACLList(cs, id)
+	}
+
+	return nil
+}
+
+// freshNetworkACLRules returns copies of the rules without any UUIDs, so all
+// their ACL items will be created.
+func freshNetworkACLRules(rules *schema.Set) *schema.Set {
+	fresh := resourceCloudStackNetworkACLRule().Schema["rule"].ZeroValue().(*schema.Set)
+
+	for _, rule := range rules.List() {
+		rule := copyNetworkACLRule(rule.(map[string]interface{}))
+		rule["uuids"] = make(map[string]interface{})
+		fresh.Add(rule)
+	}
+
+	return fresh
+}
+
+// listNetworkACLListUsers returns the IDs of the tiers and private gateways of
+// the VPC that use any of the given ACL lists.
+func listNetworkACLListUsers(d *schema.ResourceData, meta interface{}, vpcid string, aclids []string) ([]string, []string, error) {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	used := make(map[string]bool)
+	for _, id := range aclids {
+		used[id] = true
+	}
+
+	np := cs.Network.NewListNetworksParams()
+	np.SetVpcid(vpcid)
+	np.SetListall(true)
+
+	if err := setProjectid(np, cs, d); err != nil {
+		return nil, nil, err
+	}
+
+	nl, err := cs.Network.ListNetworks(np)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var networks []string
+	for _, n := range nl.Networks {
+		if used[n.Aclid] {
+			networks = append(networks, n.Id)
+		}
+	}
+
+	gp := cs.VPC.NewListPrivateGatewaysParams()
+	gp.SetVpcid(vpcid)
+	gp.SetListall(true)
+
+	if err := setProjectid(gp, cs, d); err != nil {
+		return nil, nil, err
+	}
+
+	gl, err := cs.VPC.ListPrivateGateways(gp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var gateways []string
+	for _, g := range gl.PrivateGateways {
+		if used[g.Aclid] {
+			gateways = append(gateways, g.Id)
+		}
+	}
+
+	return networks, gateways, nil
+}
+
+// replaceNetworkACLList makes all given tiers and private gateways use the
+// given ACL list.
+func replaceNetworkACLList(cs *cloudstack.CloudStackClient, aclid string, networks, gateways []string) error {
+	for _, id := range networks {
+		p := cs.NetworkACL.NewReplaceNetworkACLListParams(aclid)
+		p.SetNetworkid(id)
+
+		if _, err := cs.NetworkACL.ReplaceNetworkACLList(p); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range gateways {
+		p := cs.NetworkACL.NewReplaceNetworkACLListParams(aclid)
+		p.SetGatewayid(id)
+
+		if _, err := cs.NetworkACL.ReplaceNetworkACLList(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteStagedNetworkACLList deletes an ACL list that was used to stage a rule
+// set, including its items. Failures are only logged, as they don't affect the
+// ACL itself and any leftover list is cleaned up by the next replacement.
+func deleteStagedNetworkACLList(cs *cloudstack.CloudStackClient, aclid string) {
+	items, err := listNetworkACLItems(cs, aclid)
+	if err != nil {
+		log.Printf("[WARN] Error listing the rules of network ACL list %s: %s", aclid, err)
+		return
+	}
+
+	for _, item := range items {
+		p := cs.NetworkACL.NewDeleteNetworkACLParams(item.Id)
+		if _, err := cs.NetworkACL.DeleteNetworkACL(p); err != nil {
+			log.Printf("[WARN] Error deleting rule %s of network ACL list %s: %s", item.Id, aclid, err)
+			return
+		}
+	}
+
+	p := cs.NetworkACL.NewDeleteNetworkACLListParams(aclid)
+	if _, err := cs.NetworkACL.DeleteNetworkACLList(p); err != nil {
+		log.Printf("[WARN] Error deleting network ACL list %s: %s", aclid, err)
+	}
+}
+
+// networkACLRuleIdentity returns a key identifying the ACL items of a rule by
+// everything that cannot be updated in place.
+func networkACLRuleIdentity(rule map[string]interface{}) string {
+	rule = withRuleService(rule)
+
+	return fmt.Sprintf("%s|%s|%d|%d|%s",
+		rule["action"].(string),
+		rule["protocol"].(string),
+		rule["icmp_type"].(int),
+		rule["icmp_code"].(int),
+		rule["traffic_type"].(string),
+	)
+}
+
+// networkACLRuleItemsChanged returns true if the existing ACL items of a
+// paired rule need to be updated.
+func networkACLRuleItemsChanged(or, nr map[string]interface{}) bool {
+	return or["rule_number"].(int) != nr["rule_number"].(int) ||
+		or["description"].(string) != nr["description"].(string) ||
+		!or["cidr_list"].(*schema.Set).Equal(nr["cidr_list"].(*schema.Set))
+}
+
+// copyNetworkACLRule returns a copy of the rule with its own uuids map, so the
+// existing ACL items can be updated after new items are added to the rule.
+func copyNetworkACLRule(rule map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(rule))
+	for k, v := range rule {
+		c[k] = v
+	}
+
+	uuids := make(map[string]interface{})
+	for k, id := range rule["uuids"].(map[string]interface{}) {
+		uuids[k] = id
+	}
+	c["uuids"] = uuids
+
+	return c
+}
+
+// networkACLRuleNumbers returns the ACL item numbers to use for a rule, keyed
+// the same way as the rule UUIDs. Ports are numbered consecutively starting at
+// the rule number, ordered by their start port. An empty map is returned when
+// the rule has no explicit number.
+func networkACLRuleNumbers(rule map[string]interface{}) map[string]int {
+	numbers := make(map[string]int)
+	rule = withRuleService(rule)
+
+	number, _ := rule["rule_number"].(int)
+	if number <= 0 {
+		return numbers
+	}
+
+	switch rule["protocol"].(string) {
+	case "icmp", "all":
+		numbers[rule["protocol"].(string)] = number
+	case "tcp", "udp":
+		ps, ok := rule["ports"].(*schema.Set)
+		if !ok {
+			return numbers
+		}
+
+		var ports []string
+		for _, port := range ps.List() {
+			ports = append(ports, port.(string))
+		}
+
+		sort.Slice(ports, func(i, j int) bool {
+			pi, _ := strconv.Atoi(splitPorts.ReplaceAllString(ports[i], "$1"))
+			pj, _ := strconv.Atoi(splitPorts.ReplaceAllString(ports[j], "$1"))
+			if pi != pj {
+				return pi < pj
+			}
+			return ports[i] < ports[j]
+		})
+
+		for i, port := range ports {
+			numbers[port] = number + i
+		}
+	}
+
+	return numbers
+}
+
+// listNetworkACLItems returns all items of the ACL, ordered by their number.
+func listNetworkACLItems(cs *cloudstack.CloudStackClient, aclid string) ([]*cloudstack.NetworkACL, error) {
+	p := cs.NetworkACL.NewListNetworkACLsParams()
+	p.SetAclid(aclid)
+	p.SetListall(true)
+
+	l, err := cs.NetworkACL.ListNetworkACLs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(l.NetworkACLs, func(i, j int) bool {
+		return l.NetworkACLs[i].Number < l.NetworkACLs[j].Number
+	})
+
+	return l.NetworkACLs, nil
+}
+
+// freeNetworkACLRuleNumbers moves any existing ACL items that hold a number
+// needed by a new or changed rule to the bottom of the ACL.
+func freeNetworkACLRuleNumbers(d *schema.ResourceData, meta interface{}, nrs *schema.Set) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Map all wanted numbers to the ID of the item that should get them, or
+	// to an empty ID if the item still needs to be created
+	wanted := make(map[int]string)
+	for _, rule := range nrs.List() {
+		rule := rule.(map[string]interface{})
+		uuids := rule["uuids"].(map[string]interface{})
+
+		for k, number := range networkACLRuleNumbers(rule) {
+			id, _ := uuids[k].(string)
+			wanted[number] = id
+		}
+	}
+
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	items, err := listNetworkACLItems(cs, d.Id())
+	if err != nil {
+		return err
+	}
+
+	// An empty ACL has no numbers to free
+	if len(items) == 0 {
+		return nil
+	}
+
+	last := items[len(items)-1]
+
+	for _, item := range items {
+		id, ok := wanted[item.Number]
+		if !ok || id == item.Id {
+			continue
+		}
+
+		// Move the item to the bottom of the ACL, unless it already is the
+		// last item or the number it would get there is wanted as well
+		if _, ok := wanted[last.Number+1]; !ok && last.Id != item.Id {
+			p := cs.NetworkACL.NewMoveNetworkAclItemParams(item.Id)
+			p.SetPreviousaclruleid(last.Id)
+
+			r, err := cs.NetworkACL.MoveNetworkAclItem(p)
+			if err != nil {
+				return fmt.Errorf(
+					"Error moving ACL item %s to the bottom of the ACL: %s", item.Id, err)
+			}
+
+			item.Number = r.Number
+			last = item
+			continue
+		}
+
+		// Otherwise give the item the first free number after the last item
+		number := last.Number + 1
+		for {
+			if _, ok := wanted[number]; !ok {
+				break
+			}
+			number++
+		}
+
+		p := cs.NetworkACL.NewUpdateNetworkACLItemParams(item.Id)
+		p.SetPartialupgrade(true)
+		p.SetNumber(number)
+
+		if _, err := cs.NetworkACL.UpdateNetworkACLItem(p); err != nil {
+			return fmt.Errorf(
+				"Error freeing number %d of ACL item %s: %s", item.Number, item.Id, err)
+		}
+
+		item.Number = number
+		last = item
+	}
+
+	return nil
+}
+
+func updateNetworkACLRules(d *schema.ResourceData, meta interface{}, changed []map[string]interface{}) error {
+	var errs *multierror.Error
+
+	// The rules are updated one by one, as the order matters
+	for _, rule := range changed {
+		if err := updateNetworkACLRule(d, meta, rule); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+func updateNetworkACLRule(d *schema.ResourceData, meta interface{}, rule map[string]interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	uuids := rule["uuids"].(map[string]interface{})
+	numbers := networkACLRuleNumbers(rule)
+
+	for k, id := range uuids {
+		// We don't care about the count here, so just continue
+		if k == "%" {
+			continue
+		}
+
+		// Create a new parameter struct
+		p := cs.NetworkACL.NewUpdateNetworkACLItemParams(id.(string))
+		p.SetPartialupgrade(true)
+		p.SetReason(rule["description"].(string))
+
+		// Set the CIDR list
+		var cidrList []string
+		for _, cidr := range rule["cidr_list"].(*schema.Set).List() {
+			cidrList = append(cidrList, cidr.(string))
+		}
+		p.SetCidrlist(cidrList)
+
+		if number, ok := numbers[k]; ok {
+			p.SetNumber(number)
+		}
+
+		log.Printf("[DEBUG] Updating ACL item %s", id.(string))
+
+		// Update the ACL item
+		if _, err := cs.NetworkACL.UpdateNetworkACLItem(p); err != nil {
+			return fmt.Errorf("Error updating ACL item %s: %s", id.(string), err)
+		}
+	}
+
+	return nil
+}
+
+func resourceCloudStackNetworkACLRuleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// Make sure no two rules end up with the same number
+	used := make(map[int]bool)
+	for _, rule := range d.Get("rule").(*schema.Set).List() {
+		for _, number := range networkACLRuleNumbers(rule.(map[string]interface{})) {
+			if used[number] {
+				return fmt.Errorf(
+					"Rule number %d is used by more than one rule, rule numbers must be "+
+						"unique (rules with multiple ports use one number per port)", number)
+			}
+			used[number] = true
+		}
+	}
+
+	if !d.NewValueKnown("rule") {
+		return nil
+	}
+
+	// Parse all rules and number their entries, so rules that are shadowed by
+	// a rule with a lower number can be detected
+	var entries []*ruleEntry
+	for _, rule := range d.Get("rule").(*schema.Set).List() {
+		rule := rule.(map[string]interface{})
+
+		es, err := parseRuleEntries(rule, "ingress")
+		if err != nil {
+			return err
+		}
+
+		numbers := networkACLRuleNumbers(rule)
+		for _, e := range es {
+			e.number = numbers[e.unit]
+		}
+
+		entries = append(entries, es...)
+	}
+
+	return checkShadowedRules("cloudstack_network_acl_rule", entries)
+}
+
+// networkACLItemPorts returns the port range of a TCP or UDP ACL item. Items
+// without ports match all ports.
+func networkACLItemPorts(start, end string) (int, int, error) {
+	if start == "" {
+		return 1, 65535, nil
+	}
+
+	startPort, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if end == "" {
+		return startPort, startPort, nil
+	}
+
+	endPort, err := strconv.Atoi(end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return startPort, endPort, nil
+}
+
+func resourceCloudStackNetworkACLRuleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if _, err := importStatePassthrough(d, meta); err != nil {
+		return nil, err
+	}
+	d.Set("acl_id", d.Id())
+
+	// Get all the rules from the running environment
+	items, err := listNetworkACLItems(cs, d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an empty schema.Set to hold all rules
+	rules := resourceCloudStackNetworkACLRule().Schema["rule"].ZeroValue().(*schema.Set)
+
+	// Group all port based rules by everything but their ports
+	grouped := make(map[string]map[string]interface{})
+	numbers := make(map[string]map[string]int)
+
+	for _, r := range items {
+		// Create a set with all CIDR's
+		cidrs := &schema.Set{F: schema.HashString}
+		for _, cidr := range strings.Split(r.Cidrlist, ",") {
+			cidrs.Add(cidr)
+		}
+
+		rule := map[string]interface{}{
+			"rule_number":  r.Number,
+			"description":  r.Reason,
+			"action":       strings.ToLower(r.Action),
+			"cidr_list":    cidrs,
+			"protocol":     r.Protocol,
+			"traffic_type": strings.ToLower(r.Traffictype),
+		}
+
+		switch r.Protocol {
+		case "icmp":
+			rule["icmp_type"] = r.Icmptype
+			rule["icmp_code"] = r.Icmpcode
+			rule["uuids"] = map[string]interface{}{"icmp": r.Id}
+			rules.Add(rule)
+		case "all":
+			rule["uuids"] = map[string]interface{}{"all": r.Id}
+			rules.Add(rule)
+		case "tcp", "udp":
+			startPort, endPort, err := networkACLItemPorts(r.Startport, r.Endport)
+			if err != nil {
+				return nil, err
+			}
+
+			key := fmt.Sprintf("%s|%s|%s|%s|%s", r.Action, sortedCIDRs(r.Cidrlist),
+				r.Protocol, r.Traffictype, r.Reason)
+
+			if _, ok := grouped[key]; !ok {
+				rule["ports"] = &schema.Set{F: schema.HashString}
+				rule["uuids"] = make(map[string]interface{})
+				grouped[key] = rule
+				numbers[key] = make(map[string]int)
+			}
+
+			port := rulePort(startPort, endPort)
+			grouped[key]["ports"].(*schema.Set).Add(port)
+			grouped[key]["uuids"].(map[string]interface{})[port] = r.Id
+			numbers[key][port] = r.Number
+
+			// The rule number of the group is the lowest number of its ports
+			if r.Number < grouped[key]["rule_number"].(int) {
+				grouped[key]["rule_number"] = r.Number
+			}
+		default:
+			log.Printf(
+				"[DEBUG] Skipping ACL item %s, protocol %s cannot be imported", r.Id, r.Protocol)
+		}
+	}
+
+	for key, rule := range grouped {
+		// Only keep the rule number if the ports are numbered consecutively,
+		// otherwise the rule cannot be expressed as a single ordered rule
+		if !reflect.DeepEqual(networkACLRuleNumbers(rule), numbers[key]) {
+			rule["rule_number"] = 0
+		}
+
+		rules.Add(rule)
+	}
+
+	d.Set("rule", rules)
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func verifyNetworkACLParams(d *schema.ResourceData) error {
 	managed := d.Get("managed").(bool)
 	_, rules := d.GetOk("rule")
@@ -21,10 +21,15 @@ package cloudstack
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -73,6 +78,26 @@ func TestAccCloudStackNetworkACLRule_basic(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackNetworkACLRule_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackNetworkACLRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackNetworkACLRule_ordered,
+			},
+
+			{
+				ResourceName:            "cloudstack_network_acl_rule.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"managed", "parallelism"},
+			},
+		},
+	})
+}
+
 func TestAccCloudStackNetworkACLRule_update(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -168,6 +193,203 @@ func TestAccCloudStackNetworkACLRule_update(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackNetworkACLRule_ordered(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackNetworkACLRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackNetworkACLRule_ordered,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackNetworkACLRulesExist("cloudstack_network_acl.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network_acl_rule.foo", "rule.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"cloudstack_network_acl_rule.foo", "rule.*", map[string]string{
+							"action":      "deny",
+							"rule_number": "10",
+							"description": "block-ssh",
+						}),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"cloudstack_network_acl_rule.foo", "rule.*", map[string]string{
+							"action":      "allow",
+							"rule_number": "20",
+						}),
+				),
+			},
+
+			{
+				Config: testAccCloudStackNetworkACLRule_reordered,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackNetworkACLRulesExist("cloudstack_network_acl.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network_acl_rule.foo", "rule.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"cloudstack_network_acl_rule.foo", "rule.*", map[string]string{
+							"action":      "deny",
+							"rule_number": "20",
+							"description": "block-ssh-after-allow",
+						}),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"cloudstack_network_acl_rule.foo", "rule.*", map[string]string{
+							"action":      "allow",
+							"rule_number": "10",
+						}),
+				),
+			},
+
+			{
+				Config:      testAccCloudStackNetworkACLRule_duplicateNumber,
+				ExpectError: regexp.MustCompile("Rule number 10 is used by more than one rule"),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackNetworkACLRule_atomicReplace(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackNetworkACLRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackNetworkACLRule_atomicReplace,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackNetworkACLRulesExist("cloudstack_network_acl.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network_acl_rule.foo", "rule.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"cloudstack_network.foo", "acl_id", "cloudstack_network_acl.foo", "id"),
+				),
+			},
+
+			{
+				Config: testAccCloudStackNetworkACLRule_atomicReplaceUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackNetworkACLRulesExist("cloudstack_network_acl.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network_acl_rule.foo", "rule.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"cloudstack_network_acl_rule.foo", "rule.*", map[string]string{
+							"action":      "deny",
+							"rule_number": "10",
+						}),
+					resource.TestCheckResourceAttrPair(
+						"cloudstack_network.foo", "acl_id", "cloudstack_network_acl.foo", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestNetworkACLItemPorts(t *testing.T) {
+	cases := []struct {
+		Start string
+		End   string
+		Port  string
+		Error bool
+	}{
+		{"80", "80", "80", false},
+		{"1000", "2000", "1000-2000", false},
+		{"", "", "1-65535", false},
+		{"443", "", "443", false},
+		{"http", "", "", true},
+	}
+
+	for _, c := range cases {
+		startPort, endPort, err := networkACLItemPorts(c.Start, c.End)
+		if c.Error {
+			if err == nil {
+				t.Fatalf("expected an error for ports %q-%q", c.Start, c.End)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if port := rulePort(startPort, endPort); port != c.Port {
+			t.Fatalf("expected ports %q-%q to be %s, got %s", c.Start, c.End, c.Port, port)
+		}
+	}
+}
+
+func TestNetworkACLRuleNumbers(t *testing.T) {
+	cases := []struct {
+		Rule    map[string]interface{}
+		Numbers map[string]int
+	}{
+		// Rules without a number
+		{
+			Rule: map[string]interface{}{
+				"protocol": "all",
+			},
+			Numbers: map[string]int{},
+		},
+
+		// Rules with a single number
+		{
+			Rule: map[string]interface{}{
+				"rule_number": 5,
+				"protocol":    "icmp",
+			},
+			Numbers: map[string]int{
+				"icmp": 5,
+			},
+		},
+
+		// Ports are numbered by their start port
+		{
+			Rule: map[string]interface{}{
+				"rule_number": 100,
+				"protocol":    "tcp",
+				"ports": schema.NewSet(schema.HashString, []interface{}{
+					"8080-8090", "443", "80",
+				}),
+			},
+			Numbers: map[string]int{
+				"80":        100,
+				"443":       101,
+				"8080-8090": 102,
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		n := networkACLRuleNumbers(tc.Rule)
+		if !reflect.DeepEqual(n, tc.Numbers) {
+			t.Fatalf("%d: bad numbers: %#v", i, n)
+		}
+	}
+}
+
+func TestFreeNetworkACLRuleNumbers_emptyACL(t *testing.T) {
+	// Serve an empty list of ACL items for any request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"listnetworkaclsresponse":{}}`)
+	}))
+	defer ts.Close()
+
+	cs := cloudstack.NewClient(ts.URL, "key", "secret", false)
+
+	d := resourceCloudStackNetworkACLRule().TestResourceData()
+	d.SetId("acl-id")
+
+	nrs := resourceCloudStackNetworkACLRule().Schema["rule"].ZeroValue().(*schema.Set)
+	nrs.Add(map[string]interface{}{
+		"rule_number":  10,
+		"action":       "allow",
+		"cidr_list":    schema.NewSet(schema.HashString, []interface{}{"10.0.0.0/8"}),
+		"protocol":     "all",
+		"traffic_type": "ingress",
+		"uuids":        map[string]interface{}{},
+	})
+
+	if err := freeNetworkACLRuleNumbers(d, cs, nrs); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
 func testAccCheckCloudStackNetworkACLRulesExist(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -317,3 +539,199 @@ resource "cloudstack_network_acl_rule" "foo" {
     traffic_type = "egress"
   }
 }`
+
+const testAccCloudStackNetworkACLRule_ordered = `
+resource "cloudstack_vpc" "foo" {
+  name = "terraform-vpc"
+  cidr = "10.0.0.0/8"
+  vpc_offering = "Default VPC offering"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_network_acl" "foo" {
+  name = "terraform-acl"
+  description = "terraform-acl-text"
+  vpc_id = cloudstack_vpc.foo.id
+}
+
+resource "cloudstack_network_acl_rule" "foo" {
+  acl_id = cloudstack_network_acl.foo.id
+
+  rule {
+    rule_number = 10
+    description = "block-ssh"
+    action = "deny"
+    cidr_list = ["0.0.0.0/0"]
+    protocol = "tcp"
+    ports = ["22"]
+    traffic_type = "ingress"
+  }
+
+  rule {
+    rule_number = 20
+    action = "allow"
+    cidr_list = ["172.16.100.0/24"]
+    protocol = "tcp"
+    ports = ["22"]
+    traffic_type = "ingress"
+  }
+}`
+
+const testAccCloudStackNetworkACLRule_reordered = `
+resource "cloudstack_vpc" "foo" {
+  name = "terraform-vpc"
+  cidr = "10.0.0.0/8"
+  vpc_offering = "Default VPC offering"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_network_acl" "foo" {
+  name = "terraform-acl"
+  description = "terraform-acl-text"
+  vpc_id = cloudstack_vpc.foo.id
+}
+
+resource "cloudstack_network_acl_rule" "foo" {
+  acl_id = cloudstack_network_acl.foo.id
+
+  rule {
+    rule_number = 20
+    description = "block-ssh-after-allow"
+    action = "deny"
+    cidr_list = ["0.0.0.0/0"]
+    protocol = "tcp"
+    ports = ["22"]
+    traffic_type = "ingress"
+  }
+
+  rule {
+    rule_number = 10
+    action = "allow"
+    cidr_list = ["172.16.100.0/24"]
+    protocol = "tcp"
+    ports = ["22"]
+    traffic_type = "ingress"
+  }
+}`
+
+const testAccCloudStackNetworkACLRule_duplicateNumber = `
+resource "cloudstack_vpc" "foo" {
+  name = "terraform-vpc"
+  cidr = "10.0.0.0/8"
+  vpc_offering = "Default VPC offering"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_network_acl" "foo" {
+  name = "terraform-acl"
+  description = "terraform-acl-text"
+  vpc_id = cloudstack_vpc.foo.id
+}
+
+resource "cloudstack_network_acl_rule" "foo" {
+  acl_id = cloudstack_network_acl.foo.id
+
+  rule {
+    rule_number = 10
+    description = "block-ssh"
+    action = "deny"
+    cidr_list = ["0.0.0.0/0"]
+    protocol = "tcp"
+    ports = ["22"]
+    traffic_type = "ingress"
+  }
+
+  rule {
+    rule_number = 10
+    action = "allow"
+    cidr_list = ["172.16.100.0/24"]
+    protocol = "tcp"
+    ports = ["22"]
+    traffic_type = "ingress"
+  }
+}`
+
+const testAccCloudStackNetworkACLRule_atomicReplace = `
+resource "cloudstack_vpc" "foo" {
+  name = "terraform-vpc"
+  cidr = "10.0.0.0/8"
+  vpc_offering = "Default VPC offering"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_network_acl" "foo" {
+  name = "terraform-acl"
+  description = "terraform-acl-text"
+  vpc_id = cloudstack_vpc.foo.id
+}
+
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingForVpcNetworks"
+  vpc_id = cloudstack_vpc.foo.id
+  acl_id = cloudstack_network_acl.foo.id
+  zone = cloudstack_vpc.foo.zone
+}
+
+resource "cloudstack_network_acl_rule" "foo" {
+  acl_id = cloudstack_network_acl.foo.id
+  atomic_replace = true
+
+  rule {
+    rule_number = 20
+    action = "allow"
+    cidr_list = ["172.16.100.0/24"]
+    protocol = "tcp"
+    ports = ["22", "443"]
+    traffic_type = "ingress"
+  }
+}`
+
+const testAccCloudStackNetworkACLRule_atomicReplaceUpdate = `
+resource "cloudstack_vpc" "foo" {
+  name = "terraform-vpc"
+  cidr = "10.0.0.0/8"
+  vpc_offering = "Default VPC offering"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_network_acl" "foo" {
+  name = "terraform-acl"
+  description = "terraform-acl-text"
+  vpc_id = cloudstack_vpc.foo.id
+}
+
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingForVpcNetworks"
+  vpc_id = cloudstack_vpc.foo.id
+  acl_id = cloudstack_network_acl.foo.id
+  zone = cloudstack_vpc.foo.zone
+}
+
+resource "cloudstack_network_acl_rule" "foo" {
+  acl_id = cloudstack_network_acl.foo.id
+  atomic_replace = true
+
+  rule {
+    rule_number = 10
+    action = "deny"
+    cidr_list = ["172.16.100.128/25"]
+    protocol = "tcp"
+    ports = ["22"]
+    traffic_type = "ingress"
+  }
+
+  rule {
+    rule_number = 20
+    action = "allow"
+    cidr_list = ["172.16.100.0/24"]
+    protocol = "tcp"
+    ports = ["22", "443"]
+    traffic_type = "ingress"
+  }
+}`
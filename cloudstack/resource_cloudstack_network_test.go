@@ -21,6 +21,8 @@ package cloudstack
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
@@ -49,6 +51,38 @@ func TestAccCloudStackNetwork_basic(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackNetwork_updateOffering(t *testing.T) {
+	var network cloudstack.Network
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackNetworkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackNetwork_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackNetworkExists(
+						"cloudstack_network.foo", &network),
+					testAccCheckCloudStackNetworkBasicAttributes(&network),
+				),
+			},
+
+			{
+				Config: testAccCloudStackNetwork_updateOffering,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackNetworkExists(
+						"cloudstack_network.foo", &network),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network.foo", "network_offering", "DefaultIsolatedNetworkOfferingWithSourceNatServiceRedundant"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network.foo", "restart_required", "false"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccCloudStackNetwork_project(t *testing.T) {
 	var network cloudstack.Network
 
@@ -90,6 +124,29 @@ func TestAccCloudStackNetwork_vpc(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackNetwork_l2(t *testing.T) {
+	var network cloudstack.Network
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackNetworkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackNetwork_l2,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackNetworkExists(
+						"cloudstack_network.foo", &network),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network.foo", "cidr", ""),
+					resource.TestCheckResourceAttr(
+						"cloudstack_network.foo", "vlan", "1001"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccCloudStackNetwork_updateACL(t *testing.T) {
 	var network cloudstack.Network
 
@@ -165,6 +222,50 @@ func TestAccCloudStackNetwork_importProject(t *testing.T) {
 	})
 }
 
+func TestNetworkOfferingChangeRecreatesRouter(t *testing.T) {
+	const (
+		vr      = "a1b2c3d4-0000-4000-8000-000000000001"
+		vrOther = "a1b2c3d4-0000-4000-8000-000000000002"
+		nsx     = "a1b2c3d4-0000-4000-8000-000000000003"
+	)
+
+	offerings := map[string]string{
+		vr:      `[{"name":"Dns","provider":[{"name":"VirtualRouter"}]},{"name":"SourceNat","provider":[{"name":"VirtualRouter"}]}]`,
+		vrOther: `[{"name":"SourceNat","provider":[{"name":"VirtualRouter"}]},{"name":"Dns","provider":[{"name":"VirtualRouter"}]}]`,
+		nsx:     `[{"name":"Dns","provider":[{"name":"VirtualRouter"}]},{"name":"SourceNat","provider":[{"name":"Nsx"}]}]`,
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.FormValue("id")
+		fmt.Fprintf(w,
+			`{"listnetworkofferingsresponse":{"count":1,"networkoffering":[{"id":%q,"service":%s}]}}`,
+			id, offerings[id])
+	}))
+	defer ts.Close()
+
+	cs := cloudstack.NewClient(ts.URL, "key", "secret", false)
+
+	cases := []struct {
+		From     string
+		To       string
+		Recreate bool
+	}{
+		{vr, vrOther, false},
+		{vr, nsx, true},
+		{nsx, vrOther, true},
+	}
+
+	for _, c := range cases {
+		recreate, err := networkOfferingChangeRecreatesRouter(cs, c.From, c.To)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if recreate != c.Recreate {
+			t.Fatalf("expected changing from %s to %s to recreate the router: %t", c.From, c.To, c.Recreate)
+		}
+	}
+}
+
 func testAccCheckCloudStackNetworkExists(
 	n string, network *cloudstack.Network) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
@@ -277,6 +378,21 @@ resource "cloudstack_network" "foo" {
   }
 }`
 
+const testAccCloudStackNetwork_updateOffering = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatServiceRedundant"
+  forced = true
+  restart_trigger = "1"
+  cleanup = true
+  zone = "Sandbox-simulator"
+  tags = {
+    terraform-tag = "true"
+  }
+}`
+
 const testAccCloudStackNetwork_project = `
 resource "cloudstack_network" "foo" {
   name = "terraform-network"
@@ -304,6 +420,16 @@ resource "cloudstack_network" "foo" {
   zone = cloudstack_vpc.foo.zone
 }`
 
+const testAccCloudStackNetwork_l2 = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-l2-network"
+  display_text = "terraform-l2-network"
+  network_offering = "DefaultL2NetworkOffering"
+  vlan = 1001
+  bypass_vlan_overlap_check = true
+  zone = "Sandbox-simulator"
+}`
+
 const testAccCloudStackNetwork_acl = `
 resource "cloudstack_vpc" "foo" {
   name = "terraform-vpc"
@@ -48,6 +48,11 @@ func resourceCloudStackNIC() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"ip6_address": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
 			"virtual_machine_id": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -113,6 +118,7 @@ func resourceCloudStackNICRead(d *schema.ResourceData, meta interface{}) error {
 	for _, n := range vm.Nic {
 		if n.Id == d.Id() {
 			d.Set("ip_address", n.Ipaddress)
+			d.Set("ip6_address", n.Ip6address)
 			d.Set("network_id", n.Networkid)
 			d.Set("virtual_machine_id", vm.Id)
 			found = true
@@ -20,8 +20,10 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,6 +40,11 @@ func resourceCloudStackPortForward() *schema.Resource {
 		Read:   resourceCloudStackPortForwardRead,
 		Update: resourceCloudStackPortForwardUpdate,
 		Delete: resourceCloudStackPortForwardDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceCloudStackPortForwardImport,
+		},
+
+		CustomizeDiff: resourceCloudStackPortForwardCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"ip_address_id": {
@@ -73,11 +80,34 @@ func resourceCloudStackPortForward() *schema.Resource {
 							Required: true,
 						},
 
+						"private_end_port": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+
 						"public_port": {
 							Type:     schema.TypeInt,
 							Required: true,
 						},
 
+						"public_end_port": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+
+						"cidr_list": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+
+						"open_firewall": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+
 						"virtual_machine_id": {
 							Type:     schema.TypeString,
 							Required: true,
@@ -199,8 +229,25 @@ func createPortForward(d *schema.ResourceData, meta interface{}, forward map[str
 		p.SetNetworkid(vm.Nic[0].Networkid)
 	}
 
-	// Do not open the firewall automatically in any case
-	p.SetOpenfirewall(false)
+	// Set the end ports when forwarding a port range
+	if endPort := forward["private_end_port"].(int); endPort > 0 {
+		p.SetPrivateendport(endPort)
+	}
+	if endPort := forward["public_end_port"].(int); endPort > 0 {
+		p.SetPublicendport(endPort)
+	}
+
+	// Set the CIDR list to restrict the source addresses
+	var cidrList []string
+	for _, cidr := range forward["cidr_list"].(*schema.Set).List() {
+		cidrList = append(cidrList, cidr.(string))
+	}
+	if len(cidrList) > 0 {
+		p.SetCidrlist(cidrList)
+	}
+
+	// Only open the firewall automatically when asked to
+	p.SetOpenfirewall(forward["open_firewall"].(bool))
 
 	r, err := cs.Firewall.CreatePortForwardingRule(p)
 	if err != nil {
@@ -274,22 +321,20 @@ func resourceCloudStackPortForwardRead(d *schema.ResourceData, meta interface{})
 			// Delete the known rule so only unknown rules remain in the ruleMap
 			delete(forwardMap, id.(string))
 
-			privPort, err := strconv.Atoi(f.Privateport)
-			if err != nil {
-				return err
-			}
-
-			pubPort, err := strconv.Atoi(f.Publicport)
-			if err != nil {
+			if err := setPortForwardPorts(forward, f); err != nil {
 				return err
 			}
 
 			// Update the values
 			forward["protocol"] = f.Protocol
-			forward["private_port"] = privPort
-			forward["public_port"] = pubPort
 			forward["virtual_machine_id"] = f.Virtualmachineid
 
+			// Only update the CIDR list if we've set one ourselves, as CloudStack
+			// may return a default CIDR list otherwise
+			if forward["cidr_list"].(*schema.Set).Len() > 0 {
+				forward["cidr_list"] = portForwardCIDRs(f)
+			}
+
 			// This one is a bit tricky. We only want to update this optional value
 			// if we've set one ourselves. If not this would become a computed value
 			// and that would mess up the calculated hash of the set item.
@@ -447,6 +492,165 @@ func deletePortForward(d *schema.ResourceData, meta interface{}, forward map[str
 	return nil
 }
 
+func resourceCloudStackPortForwardCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("forward") {
+		return nil
+	}
+
+	type portRange struct {
+		protocol   string
+		start, end int
+	}
+
+	var public []portRange
+	for _, forward := range d.Get("forward").(*schema.Set).List() {
+		forward := forward.(map[string]interface{})
+
+		for _, key := range []string{"private_port", "public_port"} {
+			if port := forward[key].(int); port < 1 || port > 65535 {
+				return fmt.Errorf("%d is not a valid %s, ports must be between 1 and 65535", port, key)
+			}
+		}
+
+		privStart, privEnd := portForwardRange(forward, "private")
+		pubStart, pubEnd := portForwardRange(forward, "public")
+
+		for _, r := range [][2]int{{privStart, privEnd}, {pubStart, pubEnd}} {
+			if r[1] < r[0] || r[1] > 65535 {
+				return fmt.Errorf(
+					"%s is not a valid port range, the end port must be between the start port and 65535",
+					rulePort(r[0], r[1]))
+			}
+		}
+
+		if privEnd-privStart != pubEnd-pubStart {
+			return fmt.Errorf(
+				"The private port range %s and public port range %s must have the same size",
+				rulePort(privStart, privEnd), rulePort(pubStart, pubEnd))
+		}
+
+		for _, cidr := range forward["cidr_list"].(*schema.Set).List() {
+			if _, _, err := net.ParseCIDR(cidr.(string)); err != nil {
+				return fmt.Errorf("%q is not a valid CIDR", cidr.(string))
+			}
+		}
+
+		protocol := strings.ToLower(forward["protocol"].(string))
+		for _, r := range public {
+			if r.protocol == protocol && pubStart <= r.end && r.start <= pubEnd {
+				log.Printf(
+					"[WARN] cloudstack_port_forward: public port %s/%s overlaps with public port %s/%s",
+					protocol, rulePort(pubStart, pubEnd), r.protocol, rulePort(r.start, r.end))
+			}
+		}
+		public = append(public, portRange{protocol, pubStart, pubEnd})
+	}
+
+	return nil
+}
+
+// portForwardRange returns the private or public port range of a forward.
+func portForwardRange(forward map[string]interface{}, kind string) (int, int) {
+	start := forward[kind+"_port"].(int)
+	end, _ := forward[kind+"_end_port"].(int)
+	if end == 0 {
+		end = start
+	}
+	return start, end
+}
+
+// setPortForwardPorts updates the ports of a forward from the port forwarding
+// rule. The end ports are only set when they are configured or when the rule
+// forwards a port range.
+func setPortForwardPorts(forward map[string]interface{}, f *cloudstack.PortForwardingRule) error {
+	for _, kind := range []struct {
+		name       string
+		start, end string
+	}{
+		{"private", f.Privateport, f.Privateendport},
+		{"public", f.Publicport, f.Publicendport},
+	} {
+		start, err := strconv.Atoi(kind.start)
+		if err != nil {
+			return err
+		}
+
+		end := start
+		if kind.end != "" {
+			if end, err = strconv.Atoi(kind.end); err != nil {
+				return err
+			}
+		}
+
+		forward[kind.name+"_port"] = start
+
+		if n, _ := forward[kind.name+"_end_port"].(int); n > 0 || end != start {
+			forward[kind.name+"_end_port"] = end
+		}
+	}
+
+	return nil
+}
+
+// portForwardCIDRs returns the CIDR list of the port forwarding rule as a set.
+func portForwardCIDRs(f *cloudstack.PortForwardingRule) *schema.Set {
+	cidrs := &schema.Set{F: schema.HashString}
+	for _, cidr := range strings.Split(f.Cidrlist, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs.Add(cidr)
+		}
+	}
+	return cidrs
+}
+
+func resourceCloudStackPortForwardImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if _, err := importStatePassthrough(d, meta); err != nil {
+		return nil, err
+	}
+	d.Set("ip_address_id", d.Id())
+
+	// Get all the forwards from the running environment
+	p := cs.Firewall.NewListPortForwardingRulesParams()
+	p.SetIpaddressid(d.Id())
+	p.SetListall(true)
+
+	if err := setProjectid(p, cs, d); err != nil {
+		return nil, err
+	}
+
+	l, err := cs.Firewall.ListPortForwardingRules(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an empty schema.Set to hold all forwards
+	forwards := resourceCloudStackPortForward().Schema["forward"].ZeroValue().(*schema.Set)
+
+	for _, f := range l.PortForwardingRules {
+		forward := map[string]interface{}{
+			"protocol":           f.Protocol,
+			"private_end_port":   0,
+			"public_end_port":    0,
+			"cidr_list":          portForwardCIDRs(f),
+			"open_firewall":      false,
+			"virtual_machine_id": f.Virtualmachineid,
+			"uuid":               f.Id,
+		}
+
+		if err := setPortForwardPorts(forward, f); err != nil {
+			return nil, err
+		}
+
+		forwards.Add(forward)
+	}
+
+	d.Set("forward", forwards)
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func verifyPortForwardParams(d *schema.ResourceData, forward map[string]interface{}) error {
 	protocol := forward["protocol"].(string)
 	if protocol != "tcp" && protocol != "udp" {
@@ -47,6 +47,26 @@ func TestAccCloudStackPortForward_basic(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackPortForward_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackPortForwardDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackPortForward_basic,
+			},
+
+			{
+				ResourceName:            "cloudstack_port_forward.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"managed"},
+			},
+		},
+	})
+}
+
 func TestAccCloudStackPortForward_update(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -74,6 +94,33 @@ func TestAccCloudStackPortForward_update(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackPortForward_portRange(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackPortForwardDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackPortForward_portRange,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackPortForwardsExist("cloudstack_port_forward.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_port_forward.foo", "forward.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"cloudstack_port_forward.foo", "forward.*", map[string]string{
+							"private_port":     "2200",
+							"private_end_port": "2210",
+							"public_port":      "3200",
+							"public_end_port":  "3210",
+							"cidr_list.#":      "1",
+							"open_firewall":    "true",
+						}),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckCloudStackPortForwardsExist(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -201,3 +248,38 @@ resource "cloudstack_port_forward" "foo" {
     virtual_machine_id = cloudstack_instance.foobar.id
   }
 }`
+
+const testAccCloudStackPortForward_portRange = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  source_nat_ip = true
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_instance" "foobar" {
+  name = "terraform-test"
+  display_name = "terraform-updated"
+  service_offering= "Medium Instance"
+  network_id = cloudstack_network.foo.id
+  template = "CentOS 5.6 (64-bit) no GUI (Simulator)"
+  zone = "Sandbox-simulator"
+  expunge = true
+}
+
+resource "cloudstack_port_forward" "foo" {
+  ip_address_id = cloudstack_network.foo.source_nat_ip_id
+
+  forward {
+    protocol = "tcp"
+    private_port = 2200
+    private_end_port = 2210
+    public_port = 3200
+    public_end_port = 3210
+    cidr_list = ["192.168.10.0/24"]
+    open_firewall = true
+    virtual_machine_id = cloudstack_instance.foobar.id
+  }
+}`
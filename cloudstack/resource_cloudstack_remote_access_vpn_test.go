@@ -0,0 +1,137 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccCloudStackRemoteAccessVPN_basic(t *testing.T) {
+	var vpn cloudstack.RemoteAccessVpn
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackRemoteAccessVPNDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackRemoteAccessVPN_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackRemoteAccessVPNExists(
+						"cloudstack_remote_access_vpn.foo", &vpn),
+					resource.TestCheckResourceAttr(
+						"cloudstack_remote_access_vpn.foo", "ip_range", "10.1.2.2-10.1.2.8"),
+					resource.TestCheckResourceAttrSet(
+						"cloudstack_remote_access_vpn.foo", "preshared_key"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackRemoteAccessVPN_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackRemoteAccessVPNDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackRemoteAccessVPN_basic,
+			},
+
+			{
+				ResourceName:            "cloudstack_remote_access_vpn.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"open_firewall"},
+			},
+		},
+	})
+}
+
+func testAccCheckCloudStackRemoteAccessVPNExists(
+	n string, vpn *cloudstack.RemoteAccessVpn) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No remote access VPN ID is set")
+		}
+
+		cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+		v, _, err := cs.VPN.GetRemoteAccessVpnByID(rs.Primary.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if v.Id != rs.Primary.ID {
+			return fmt.Errorf("Remote access VPN not found")
+		}
+
+		*vpn = *v
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackRemoteAccessVPNDestroy(s *terraform.State) error {
+	cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "cloudstack_remote_access_vpn" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No remote access VPN ID is set")
+		}
+
+		_, _, err := cs.VPN.GetRemoteAccessVpnByID(rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Remote access VPN %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+const testAccCloudStackRemoteAccessVPN_basic = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  source_nat_ip = true
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_remote_access_vpn" "foo" {
+  ip_address_id = cloudstack_network.foo.source_nat_ip_id
+  ip_range = "10.1.2.2-10.1.2.8"
+}`
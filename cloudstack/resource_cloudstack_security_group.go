@@ -32,6 +32,7 @@ func resourceCloudStackSecurityGroup() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceCloudStackSecurityGroupCreate,
 		Read:   resourceCloudStackSecurityGroupRead,
+		Update: resourceCloudStackSecurityGroupUpdate,
 		Delete: resourceCloudStackSecurityGroupDelete,
 		Importer: &schema.ResourceImporter{
 			State: importStatePassthrough,
@@ -41,9 +42,9 @@ func resourceCloudStackSecurityGroup() *schema.Resource {
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
+			// The API can only update the name of a security group
 			"description": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -57,6 +58,8 @@ func resourceCloudStackSecurityGroup() *schema.Resource {
 				Computed: true,
 				ForceNew: true,
 			},
+
+			"tags": tagsSchema(),
 		},
 	}
 }
@@ -88,6 +91,11 @@ func resourceCloudStackSecurityGroupCreate(d *schema.ResourceData, meta interfac
 
 	d.SetId(r.Id)
 
+	// Set tags if necessary
+	if err := setTags(cs, d, "SecurityGroup"); err != nil {
+		return fmt.Errorf("Error setting tags on security group %s: %s", name, err)
+	}
+
 	return resourceCloudStackSecurityGroupRead(d, meta)
 }
 
@@ -112,12 +120,38 @@ func resourceCloudStackSecurityGroupRead(d *schema.ResourceData, meta interface{
 	// Update the config
 	d.Set("name", sg.Name)
 	d.Set("description", sg.Description)
+	d.Set("tags", tagsToMap(sg.Tags))
 
 	setValueOrID(d, "project", sg.Project, sg.Projectid)
 
 	return nil
 }
 
+func resourceCloudStackSecurityGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	name := d.Get("name").(string)
+
+	// Check if the name is changed and if so, update the security group
+	if d.HasChange("name") {
+		p := cs.SecurityGroup.NewUpdateSecurityGroupParams(d.Id())
+		p.SetName(name)
+
+		if _, err := cs.SecurityGroup.UpdateSecurityGroup(p); err != nil {
+			return fmt.Errorf("Error updating the name of security group %s: %s", name, err)
+		}
+	}
+
+	// Update tags if they have changed
+	if d.HasChange("tags") {
+		if err := updateTags(cs, d, "SecurityGroup"); err != nil {
+			return fmt.Errorf("Error updating tags on security group %s: %s", name, err)
+		}
+	}
+
+	return resourceCloudStackSecurityGroupRead(d, meta)
+}
+
 func resourceCloudStackSecurityGroupDelete(d *schema.ResourceData, meta interface{}) error {
 	cs := meta.(*cloudstack.CloudStackClient)
 
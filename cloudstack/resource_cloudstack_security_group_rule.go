@@ -20,8 +20,10 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -50,6 +52,12 @@ func resourceCloudStackSecurityGroupRule() *schema.Resource {
 		Update: resourceCloudStackSecurityGroupRuleUpdate,
 		Delete: resourceCloudStackSecurityGroupRuleDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceCloudStackSecurityGroupRuleImport,
+		},
+
+		CustomizeDiff: resourceCloudStackSecurityGroupRuleCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"security_group_id": {
 				Type:     schema.TypeString,
@@ -57,9 +65,15 @@ func resourceCloudStackSecurityGroupRule() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"managed": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"rule": {
 				Type:     schema.TypeSet,
-				Required: true,
+				Optional: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"cidr_list": {
@@ -69,9 +83,14 @@ func resourceCloudStackSecurityGroupRule() *schema.Resource {
 							Set:      schema.HashString,
 						},
 
+						"service": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
 						"protocol": {
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
 						},
 
 						"icmp_type": {
@@ -130,6 +149,11 @@ func resourceCloudStackSecurityGroupRule() *schema.Resource {
 }
 
 func resourceCloudStackSecurityGroupRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	// Make sure all required parameters are there
+	if err := verifySecurityGroupParams(d); err != nil {
+		return err
+	}
+
 	// We need to set this upfront in order to be able to save a partial state
 	d.SetId(d.Get("security_group_id").(string))
 
@@ -167,6 +191,9 @@ func createSecurityGroupRules(d *schema.ResourceData, meta interface{}, rules *s
 			defer wg.Done()
 			sem <- struct{}{}
 
+			// Expand the referenced service, if any
+			rule = withRuleService(rule)
+
 			// Make sure all required parameters are there
 			if err := verifySecurityGroupRuleParams(d, rule); err != nil {
 				errs = multierror.Append(errs, err)
@@ -198,13 +225,19 @@ func createSecurityGroupRules(d *schema.ResourceData, meta interface{}, rules *s
 
 			if usgList, ok := rule["user_security_group_list"].(*schema.Set); ok && usgList.Len() > 0 {
 				for _, usg := range usgList.List() {
-					sg, _, err := cs.SecurityGroup.GetSecurityGroupByName(
-						usg.(string),
-						cloudstack.WithProject(d.Get("project").(string)),
-					)
-					if err != nil {
-						errs = multierror.Append(errs, err)
-						continue
+					// Security groups of other accounts or projects are referenced
+					// as account/group, others are looked up to get their account
+					account, name := splitSecurityGroupReference(usg.(string))
+					if account == "" {
+						sg, _, err := cs.SecurityGroup.GetSecurityGroupByName(
+							name,
+							cloudstack.WithProject(d.Get("project").(string)),
+						)
+						if err != nil {
+							errs = multierror.Append(errs, err)
+							continue
+						}
+						account = sg.Account
 					}
 
 					// Create a new parameter struct
@@ -216,10 +249,10 @@ func createSecurityGroupRules(d *schema.ResourceData, meta interface{}, rules *s
 					}
 
 					p.SetSecuritygroupid(d.Id())
-					p.SetUsersecuritygrouplist(map[string]string{sg.Account: usg.(string)})
+					p.SetUsersecuritygrouplist(map[string]string{account: name})
 
 					// Create a single rule
-					err = createSecurityGroupRule(d, meta, rule, p, usg.(string))
+					err := createSecurityGroupRule(d, meta, rule, p, usg.(string))
 					if err != nil {
 						errs = multierror.Append(errs, err)
 					}
@@ -228,7 +261,7 @@ func createSecurityGroupRules(d *schema.ResourceData, meta interface{}, rules *s
 
 			// If we have at least one UUID, we need to save the rule
 			if len(rule["uuids"].(map[string]interface{})) > 0 {
-				rules.Add(rule)
+				rules.Add(collapseRuleService(rule))
 			}
 
 			<-sem
@@ -248,7 +281,7 @@ func createSecurityGroupRule(d *schema.ResourceData, meta interface{}, rule map[
 	p.SetProtocol(rule["protocol"].(string))
 
 	// If the protocol is ICMP set the needed ICMP parameters
-	if rule["protocol"].(string) == "icmp" {
+	if _, ok := uuids[uuid+"icmp"]; !ok && rule["protocol"].(string) == "icmp" {
 		p.SetIcmptype(rule["icmp_type"].(int))
 		p.SetIcmpcode(rule["icmp_code"].(int))
 
@@ -360,7 +393,8 @@ func resourceCloudStackSecurityGroupRuleRead(d *schema.ResourceData, meta interf
 	// Read all rules that are configured
 	if rs := d.Get("rule").(*schema.Set); rs.Len() > 0 {
 		for _, rule := range rs.List() {
-			rule := rule.(map[string]interface{})
+			// Expand the referenced service, if any, so its ports can be read
+			rule := withRuleService(rule.(map[string]interface{}))
 
 			// First get any existing values
 			cidrList, cidrListOK := rule["cidr_list"].(*schema.Set)
@@ -382,10 +416,59 @@ func resourceCloudStackSecurityGroupRuleRead(d *schema.ResourceData, meta interf
 				}
 			}
 
-			rules.Add(rule)
+			// Only keep the rule if at least one of its rules still exists
+			if len(rule["uuids"].(map[string]interface{})) > 0 {
+				rules.Add(collapseRuleService(rule))
+			}
+		}
+	}
+
+	// If this is a managed security group, add all unknown rules into dummy rules
+	managed := d.Get("managed").(bool)
+	if managed {
+		// Collect the IDs of all known rules
+		known := make(map[string]bool)
+		for _, rule := range rules.List() {
+			for _, id := range rule.(map[string]interface{})["uuids"].(map[string]interface{}) {
+				known[id.(string)] = true
+			}
+		}
+
+		for traffic, sgRules := range map[string][]cloudstack.SecurityGroupRule{
+			"ingress": sg.Ingressrule,
+			"egress":  sg.Egressrule,
+		} {
+			for _, r := range sgRules {
+				if known[r.Ruleid] {
+					continue
+				}
+
+				// We need to create and add a dummy value to a schema.Set as the
+				// cidr_list is used to identify the rule
+				cidrs := &schema.Set{F: schema.HashString}
+				cidrs.Add(r.Ruleid)
+
+				// Make a dummy rule to hold the unknown UUID, the traffic type
+				// is needed to revoke the rule
+				rule := map[string]interface{}{
+					"cidr_list":    cidrs,
+					"protocol":     r.Ruleid,
+					"traffic_type": traffic,
+					"uuids":        map[string]interface{}{r.Ruleid: r.Ruleid},
+				}
+
+				// Add the dummy rule to the rules set
+				rules.Add(rule)
+			}
 		}
 	}
 
+	if rules.Len() > 0 {
+		d.Set("rule", rules)
+	} else if !managed {
+		d.SetId("")
+	}
+
 	return nil
 }
 
@@ -414,7 +497,7 @@ func readSecurityGroupRule(sg *cloudstack.SecurityGroup, ruleIndex map[string]in
 		}
 
 		if r.Securitygroupname != "" {
-			rule["user_security_group_list"].(*schema.Set).Add(r.Securitygroupname)
+			rule["user_security_group_list"].(*schema.Set).Add(uuid)
 		}
 
 		rule["protocol"] = r.Protocol
@@ -445,13 +528,15 @@ func readSecurityGroupRule(sg *cloudstack.SecurityGroup, ruleIndex map[string]in
 
 				r := sgRules[idx]
 
-				// Create a set with all CIDR's
-				cidrs := &schema.Set{F: schema.HashString}
-				for _, cidr := range strings.Split(r.Cidr, ",") {
-					cidrs.Add(cidr)
+				// Update the values
+				if r.Cidr != "" {
+					rule["cidr_list"].(*schema.Set).Add(r.Cidr)
+				}
+
+				if r.Securitygroupname != "" {
+					rule["user_security_group_list"].(*schema.Set).Add(uuid)
 				}
 
-				// Update the values
 				rule["protocol"] = r.Protocol
 				ports.Add(port)
 			}
@@ -465,6 +550,11 @@ func readSecurityGroupRule(sg *cloudstack.SecurityGroup, ruleIndex map[string]in
 }
 
 func resourceCloudStackSecurityGroupRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Make sure all required parameters are there
+	if err := verifySecurityGroupParams(d); err != nil {
+		return err
+	}
+
 	// Check if the rule set as a whole has changed
 	if d.HasChange("rule") {
 		o, n := d.GetChange("rule")
@@ -477,9 +567,14 @@ func resourceCloudStackSecurityGroupRuleUpdate(d *schema.ResourceData, meta inte
 		// set to make sure we end up in a consistent state
 		rules := o.(*schema.Set).Intersection(n.(*schema.Set))
 
-		// First loop through all the old rules destroy them
-		if ors.Len() > 0 {
-			err := deleteSecurityGroupRules(d, meta, rules, ors)
+		// Pair changed rules that only differ in their CIDRs, security groups
+		// or ports, so only the (cidr, port) pairs that are actually added or
+		// removed are touched
+		pairRules(ors, nrs, securityGroupRuleIdentity, securityGroupRuleUnits)
+
+		// First loop through all the new rules and create (before destroy) them
+		if nrs.Len() > 0 {
+			err := createSecurityGroupRules(d, meta, rules, nrs)
 
 			// We need to update this first to preserve the correct state
 			d.Set("rule", rules)
@@ -489,9 +584,9 @@ func resourceCloudStackSecurityGroupRuleUpdate(d *schema.ResourceData, meta inte
 			}
 		}
 
-		// Then loop through all the new rules and delete them
-		if nrs.Len() > 0 {
-			err := createSecurityGroupRules(d, meta, rules, nrs)
+		// Then loop through all the old rules and delete them
+		if ors.Len() > 0 {
+			err := deleteSecurityGroupRules(d, meta, rules, ors)
 
 			// We need to update this first to preserve the correct state
 			d.Set("rule", rules)
@@ -599,6 +694,204 @@ func deleteSecurityGroupRule(d *schema.ResourceData, meta interface{}, rule map[
 	return nil
 }
 
+// securityGroupRuleIdentity returns a key identifying a rule by everything but
+// its CIDRs, security groups and ports.
+func securityGroupRuleIdentity(rule map[string]interface{}) string {
+	rule = withRuleService(rule)
+
+	return fmt.Sprintf("%s|%d|%d|%s",
+		rule["protocol"].(string),
+		rule["icmp_type"].(int),
+		rule["icmp_code"].(int),
+		rule["traffic_type"].(string),
+	)
+}
+
+// securityGroupRuleUnits returns the keys used in the uuids map of a rule, one
+// for every combination of a CIDR or security group and a port.
+func securityGroupRuleUnits(rule map[string]interface{}) []string {
+	var sources []string
+	for _, key := range []string{"cidr_list", "user_security_group_list"} {
+		if s, ok := rule[key].(*schema.Set); ok {
+			for _, source := range s.List() {
+				sources = append(sources, source.(string))
+			}
+		}
+	}
+
+	var units []string
+	for _, source := range sources {
+		for _, port := range ruleUnits(rule) {
+			units = append(units, source+port)
+		}
+	}
+
+	return units
+}
+
+func resourceCloudStackSecurityGroupRuleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	return verifyRuleSet(d, "cloudstack_security_group_rule", "rule", "ingress")
+}
+
+func resourceCloudStackSecurityGroupRuleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if _, err := importStatePassthrough(d, meta); err != nil {
+		return nil, err
+	}
+	d.Set("security_group_id", d.Id())
+
+	// Get the security group details
+	sg, _, err := cs.SecurityGroup.GetSecurityGroupByID(
+		d.Id(),
+		cloudstack.WithProject(d.Get("project").(string)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	type source struct {
+		traffic  string
+		protocol string
+		icmpType int
+		icmpCode int
+		name     string
+		usg      bool
+		ports    map[string]string
+	}
+
+	// First collect the ports (and their rule IDs) per CIDR or security group
+	var sources []*source
+	index := make(map[string]*source)
+
+	collect := func(traffic string, rules []cloudstack.SecurityGroupRule) {
+		for _, r := range rules {
+			s := &source{
+				traffic:  traffic,
+				protocol: r.Protocol,
+				name:     r.Cidr,
+				ports:    make(map[string]string),
+			}
+
+			if r.Securitygroupname != "" {
+				s.name = r.Securitygroupname
+				s.usg = true
+
+				// Reference security groups of other accounts as account/group
+				if r.Account != "" && r.Account != sg.Account {
+					s.name = r.Account + "/" + r.Securitygroupname
+				}
+			}
+
+			port := "icmp"
+			switch r.Protocol {
+			case "icmp":
+				s.icmpType = r.Icmptype
+				s.icmpCode = r.Icmpcode
+			case "tcp", "udp":
+				port = rulePort(r.Startport, r.Endport)
+			default:
+				log.Printf(
+					"[DEBUG] Skipping security group rule %s, protocol %s cannot be imported",
+					r.Ruleid, r.Protocol)
+				continue
+			}
+
+			key := fmt.Sprintf("%s|%s|%d|%d|%s|%t",
+				traffic, s.protocol, s.icmpType, s.icmpCode, s.name, s.usg)
+			if _, ok := index[key]; !ok {
+				index[key] = s
+				sources = append(sources, s)
+			}
+
+			index[key].ports[port] = r.Ruleid
+		}
+	}
+
+	collect("ingress", sg.Ingressrule)
+	collect("egress", sg.Egressrule)
+
+	// Create an empty schema.Set to hold all rules
+	rules := resourceCloudStackSecurityGroupRule().Schema["rule"].ZeroValue().(*schema.Set)
+
+	// Then group all CIDRs and security groups that share the same ports
+	grouped := make(map[string]map[string]interface{})
+
+	for _, s := range sources {
+		var ports []string
+		for port := range s.ports {
+			ports = append(ports, port)
+		}
+		sort.Strings(ports)
+
+		key := fmt.Sprintf("%s|%s|%d|%d|%s",
+			s.traffic, s.protocol, s.icmpType, s.icmpCode, strings.Join(ports, ","))
+
+		rule, ok := grouped[key]
+		if !ok {
+			rule = map[string]interface{}{
+				"cidr_list":                &schema.Set{F: schema.HashString},
+				"user_security_group_list": &schema.Set{F: schema.HashString},
+				"protocol":                 s.protocol,
+				"traffic_type":             s.traffic,
+				"uuids":                    make(map[string]interface{}),
+			}
+
+			if s.protocol == "icmp" {
+				rule["icmp_type"] = s.icmpType
+				rule["icmp_code"] = s.icmpCode
+			} else {
+				rule["ports"] = &schema.Set{F: schema.HashString}
+			}
+
+			grouped[key] = rule
+		}
+
+		if s.usg {
+			rule["user_security_group_list"].(*schema.Set).Add(s.name)
+		} else {
+			rule["cidr_list"].(*schema.Set).Add(s.name)
+		}
+
+		for port, id := range s.ports {
+			if port != "icmp" {
+				rule["ports"].(*schema.Set).Add(port)
+			}
+			rule["uuids"].(map[string]interface{})[s.name+port] = id
+		}
+	}
+
+	for _, rule := range grouped {
+		rules.Add(rule)
+	}
+
+	d.Set("rule", rules)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// splitSecurityGroupReference splits a user security group reference of the
+// form account/group into its account and group name. The account is empty
+// when only a group name is given.
+func splitSecurityGroupReference(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return "", ref
+}
+
+func verifySecurityGroupParams(d *schema.ResourceData) error {
+	managed := d.Get("managed").(bool)
+	_, rules := d.GetOk("rule")
+
+	if !rules && !managed {
+		return fmt.Errorf(
+			"You must supply at least one 'rule' when not using the 'managed' security group feature")
+	}
+
+	return nil
+}
+
 func verifySecurityGroupRuleParams(d *schema.ResourceData, rule map[string]interface{}) error {
 	cidrList, cidrListOK := rule["cidr_list"].(*schema.Set)
 	usgList, usgListOK := rule["user_security_group_list"].(*schema.Set)
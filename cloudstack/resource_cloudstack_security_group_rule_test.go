@@ -67,6 +67,26 @@ func TestAccCloudStackSecurityGroupRule_basic(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackSecurityGroupRule_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackSecurityGroupRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackSecurityGroupRule_basic,
+			},
+
+			{
+				ResourceName:            "cloudstack_security_group_rule.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"managed", "parallelism"},
+			},
+		},
+	})
+}
+
 func TestAccCloudStackSecurityGroupRule_update(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -142,6 +162,78 @@ func TestAccCloudStackSecurityGroupRule_update(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackSecurityGroupRule_managed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackSecurityGroupRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackSecurityGroupRule_managed,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackSecurityGroupRulesExist("cloudstack_security_group.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_security_group_rule.foo", "rule.#", "1"),
+				),
+			},
+
+			{
+				PreConfig: testAccCloudStackSecurityGroupRule_addUnknownRule(t),
+				Config:    testAccCloudStackSecurityGroupRule_managed,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackSecurityGroupRulesExist("cloudstack_security_group.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_security_group_rule.foo", "rule.#", "1"),
+					testAccCheckCloudStackSecurityGroupRuleCount("cloudstack_security_group.foo", 1),
+				),
+			},
+		},
+	})
+}
+
+func testAccCloudStackSecurityGroupRule_addUnknownRule(t *testing.T) func() {
+	return func() {
+		cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+
+		sg, _, err := cs.SecurityGroup.GetSecurityGroupByName("terraform-security-group-foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p := cs.SecurityGroup.NewAuthorizeSecurityGroupIngressParams()
+		p.SetSecuritygroupid(sg.Id)
+		p.SetCidrlist([]string{"10.0.0.0/8"})
+		p.SetProtocol("tcp")
+		p.SetStartport(22)
+		p.SetEndport(22)
+
+		if _, err := cs.SecurityGroup.AuthorizeSecurityGroupIngress(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testAccCheckCloudStackSecurityGroupRuleCount(n string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+		sg, _, err := cs.SecurityGroup.GetSecurityGroupByID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if c := len(sg.Ingressrule) + len(sg.Egressrule); c != count {
+			return fmt.Errorf("Expected %d security group rules, got %d", count, c)
+		}
+
+		return nil
+	}
+}
+
 func testAccCheckCloudStackSecurityGroupRulesExist(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -291,3 +383,20 @@ resource "cloudstack_security_group_rule" "foo" {
 
 	depends_on = ["cloudstack_security_group.bar"]
 }`
+
+const testAccCloudStackSecurityGroupRule_managed = `
+resource "cloudstack_security_group" "foo" {
+  name = "terraform-security-group-foo"
+  description = "terraform-security-group-text"
+}
+
+resource "cloudstack_security_group_rule" "foo" {
+  security_group_id = cloudstack_security_group.foo.id
+  managed = true
+
+  rule {
+    cidr_list = ["172.18.100.0/24"]
+    protocol = "tcp"
+    ports = ["443"]
+  }
+}`
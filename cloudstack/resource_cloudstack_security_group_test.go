@@ -47,6 +47,37 @@ func TestAccCloudStackSecurityGroup_basic(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackSecurityGroup_update(t *testing.T) {
+	var sg cloudstack.SecurityGroup
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackSecurityGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackSecurityGroup_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackSecurityGroupExists(
+						"cloudstack_security_group.foo", &sg),
+					testAccCheckCloudStackSecurityGroupBasicAttributes(&sg),
+				),
+			},
+
+			{
+				Config: testAccCloudStackSecurityGroup_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackSecurityGroupExists(
+						"cloudstack_security_group.foo", &sg),
+					resource.TestCheckResourceAttr(
+						"cloudstack_security_group.foo", "name", "terraform-security-group-renamed"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_security_group.foo", "tags.terraform-tag", "true"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccCloudStackSecurityGroup_import(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -136,3 +167,13 @@ resource "cloudstack_security_group" "foo" {
   name = "terraform-security-group"
 	description = "terraform-security-group-text"
 }`
+
+const testAccCloudStackSecurityGroup_update = `
+resource "cloudstack_security_group" "foo" {
+  name = "terraform-security-group-renamed"
+  description = "terraform-security-group-text"
+
+  tags = {
+    terraform-tag = "true"
+  }
+}`
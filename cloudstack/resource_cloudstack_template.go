@@ -22,11 +22,13 @@ package cloudstack
 import (
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceCloudStackTemplate() *schema.Resource {
@@ -50,12 +52,14 @@ func resourceCloudStackTemplate() *schema.Resource {
 
 			"format": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				Computed: true,
 			},
 
 			"hypervisor": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				Computed: true,
 				ForceNew: true,
 			},
 
@@ -65,24 +69,67 @@ func resourceCloudStackTemplate() *schema.Resource {
 			},
 
 			"url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"url", "source_file", "source_volume_id", "source_snapshot_id"},
+			},
+
+			"source_file": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 				ForceNew: true,
 			},
 
-			"project": {
+			"source_volume_id": {
 				Type:     schema.TypeString,
 				Optional: true,
-				Computed: true,
 				ForceNew: true,
 			},
 
-			"zone": {
+			"source_snapshot_id": {
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
 			},
 
+			"stop_source_instance": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"copy_source_tags": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ForceNew:      true,
+				ConflictsWith: []string{"tags"},
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"zone": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"zones"},
+			},
+
+			"zones": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ForceNew:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Set:           schema.HashString,
+				ConflictsWith: []string{"zone"},
+			},
+
 			"is_dynamically_scalable": {
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -115,6 +162,59 @@ func resourceCloudStackTemplate() *schema.Resource {
 				Computed: true,
 			},
 
+			"requires_hvm": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+
+			"ssh_key_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+
+			"checksum": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"source_file"},
+			},
+
+			"direct_download": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"deploy_as_is": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"template_tag": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"boot_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{"LEGACY", "SECURE"}, false),
+			},
+
+			"details": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
 			"is_ready": {
 				Type:     schema.TypeBool,
 				Computed: true,
@@ -126,6 +226,8 @@ func resourceCloudStackTemplate() *schema.Resource {
 				Default:  300,
 			},
 
+			"zone_status": imageZoneStatusSchema(),
+
 			"tags": tagsSchema(),
 		},
 	}
@@ -138,6 +240,18 @@ func resourceCloudStackTemplateCreate(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
+	// Upload the template if a local file is supplied
+	if _, ok := d.GetOk("source_file"); ok {
+		return uploadCloudStackTemplate(d, meta)
+	}
+
+	// Capture the template if a volume or snapshot is supplied
+	_, volume := d.GetOk("source_volume_id")
+	_, snapshot := d.GetOk("source_snapshot_id")
+	if volume || snapshot {
+		return captureCloudStackTemplate(d, meta)
+	}
+
 	name := d.Get("name").(string)
 
 	// Compute/set the display text
@@ -155,36 +269,20 @@ func resourceCloudStackTemplateCreate(d *schema.ResourceData, meta interface{})
 		d.Get("url").(string),
 	)
 
-	// Retrieve the os_type ID
-	ostypeid, e := retrieveID(cs, "os_type", d.Get("os_type").(string))
-	if e == nil {
-		p.SetOstypeid(ostypeid)
-	}
-
-	// Set optional parameters
-	if v, ok := d.GetOk("is_dynamically_scalable"); ok {
-		p.SetIsdynamicallyscalable(v.(bool))
-	}
-
-	if v, ok := d.GetOk("is_extractable"); ok {
-		p.SetIsextractable(v.(bool))
-	}
-
-	if v, ok := d.GetOk("is_featured"); ok {
-		p.SetIsfeatured(v.(bool))
-	}
+	// Set the os_type and optional parameters
+	setTemplateParams(cs, d, p)
 
-	if v, ok := d.GetOk("is_public"); ok {
-		p.SetIspublic(v.(bool))
+	if v, ok := d.GetOk("checksum"); ok {
+		p.SetChecksum(v.(string))
 	}
 
-	if v, ok := d.GetOk("password_enabled"); ok {
-		p.SetPasswordenabled(v.(bool))
+	if v, ok := d.GetOk("direct_download"); ok {
+		p.SetDirectdownload(v.(bool))
 	}
 
-	// Retrieve the zone ID
+	// Retrieve the zone ID(s)
 	if v, ok := d.GetOk("zone"); ok {
-		if v.(string) != "all" {
+		if !isAllZones(v.(string)) {
 			zoneid, e := retrieveID(cs, "zone", v.(string))
 			if e != nil {
 				return e.Error()
@@ -195,6 +293,18 @@ func resourceCloudStackTemplateCreate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
+	if v, ok := d.GetOk("zones"); ok {
+		var zoneids []string
+		for _, zone := range v.(*schema.Set).List() {
+			zoneid, e := retrieveID(cs, "zone", zone.(string))
+			if e != nil {
+				return e.Error()
+			}
+			zoneids = append(zoneids, zoneid)
+		}
+		p.SetZoneids(zoneids)
+	}
+
 	// If there is a project supplied, we retrieve and set the project id
 	if err := setProjectid(p, cs, d); err != nil {
 		return err
@@ -214,58 +324,277 @@ func resourceCloudStackTemplateCreate(d *schema.ResourceData, meta interface{})
 	}
 
 	// Wait until the template is ready to use, or timeout with an error...
-	currentTime := time.Now().Unix()
-	timeout := int64(d.Get("is_ready_timeout").(int))
-	for {
-		// Start with the sleep so the register action has a few seconds
-		// to process the registration correctly. Without this wait
-		time.Sleep(10 * time.Second)
+	return waitForImageReady(d, meta, "Template", resourceCloudStackTemplateRead)
+}
+
+func uploadCloudStackTemplate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
 
-		err := resourceCloudStackTemplateRead(d, meta)
+	name := d.Get("name").(string)
+	file := d.Get("source_file").(string)
+
+	// Compute/set the display text
+	displaytext := d.Get("display_text").(string)
+	if displaytext == "" {
+		displaytext = name
+	}
+
+	// Retrieve the zone ID
+	zoneid, e := retrieveID(cs, "zone", d.Get("zone").(string))
+	if e != nil {
+		return e.Error()
+	}
+
+	// Create a new parameter struct
+	p := cs.Template.NewGetUploadParamsForTemplateParams(
+		displaytext,
+		d.Get("format").(string),
+		d.Get("hypervisor").(string),
+		name,
+		zoneid,
+	)
+
+	// Set the os_type and optional parameters
+	setTemplateParams(cs, d, p)
+
+	// Let secondary storage verify the uploaded file
+	checksum, err := fileChecksum(file)
+	if err != nil {
+		return err
+	}
+	p.SetChecksum(checksum)
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return err
+	}
+
+	// Request the parameters needed to upload the template
+	r, err := cs.Template.GetUploadParamsForTemplate(p)
+	if err != nil {
+		return fmt.Errorf("Error creating template %s: %s", name, err)
+	}
+
+	d.SetId(r.Id)
+
+	err = uploadFile(file, &uploadParams{
+		postURL:   r.PostURL,
+		expires:   r.Expires,
+		metadata:  r.Metadata,
+		signature: r.Signature,
+	})
+	if err != nil {
+		return fmt.Errorf("Error uploading template %s: %s", name, err)
+	}
+
+	// Set tags if necessary
+	if err = setTags(cs, d, "Template"); err != nil {
+		return fmt.Errorf("Error setting tags on the template %s: %s", name, err)
+	}
+
+	// Wait until the template is ready to use, or timeout with an error...
+	return waitForImageReady(d, meta, "Template", resourceCloudStackTemplateRead)
+}
+
+func captureCloudStackTemplate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	name := d.Get("name").(string)
+
+	// Compute/set the display text
+	displaytext := d.Get("display_text").(string)
+	if displaytext == "" {
+		displaytext = name
+	}
+
+	// Retrieve the os_type ID
+	ostypeid, e := retrieveID(cs, "os_type", d.Get("os_type").(string))
+	if e != nil {
+		return e.Error()
+	}
+
+	// Create a new parameter struct
+	p := cs.Template.NewCreateTemplateParams(displaytext, name, ostypeid)
+
+	// Set optional parameters
+	if v, ok := d.GetOk("is_dynamically_scalable"); ok {
+		p.SetIsdynamicallyscalable(v.(bool))
+	}
+
+	if v, ok := d.GetOk("is_featured"); ok {
+		p.SetIsfeatured(v.(bool))
+	}
+
+	if v, ok := d.GetOk("is_public"); ok {
+		p.SetIspublic(v.(bool))
+	}
+
+	if v, ok := d.GetOk("password_enabled"); ok {
+		p.SetPasswordenabled(v.(bool))
+	}
+
+	if v, ok := d.GetOk("requires_hvm"); ok {
+		p.SetRequireshvm(v.(bool))
+	}
+
+	if v, ok := d.GetOk("ssh_key_enabled"); ok {
+		p.SetSshkeyenabled(v.(bool))
+	}
+
+	if v, ok := d.GetOk("template_tag"); ok {
+		p.SetTemplatetag(v.(string))
+	}
+
+	if details := templateDetails(d); len(details) > 0 {
+		p.SetDetails(details)
+	}
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return err
+	}
+
+	var tags []cloudstack.Tags
+	var vmid string
+
+	if volumeid, ok := d.GetOk("source_volume_id"); ok {
+		v, _, err := cs.Volume.GetVolumeByID(
+			volumeid.(string),
+			cloudstack.WithProject(d.Get("project").(string)),
+		)
 		if err != nil {
-			return err
+			return fmt.Errorf("Error retrieving source volume %s: %s", volumeid.(string), err)
 		}
 
-		if d.Get("is_ready").(bool) {
-			return nil
+		p.SetVolumeid(v.Id)
+		tags = v.Tags
+
+		// Stop the instance using the volume, so the captured disk is consistent
+		if v.Virtualmachineid != "" && d.Get("stop_source_instance").(bool) {
+			vm, _, err := cs.VirtualMachine.GetVirtualMachineByID(
+				v.Virtualmachineid,
+				cloudstack.WithProject(d.Get("project").(string)),
+			)
+			if err != nil {
+				return fmt.Errorf("Error retrieving source instance %s: %s", v.Virtualmachineid, err)
+			}
+
+			if vm.State == "Running" {
+				log.Printf("[DEBUG] Stopping instance %s before capturing template %s", vm.Name, name)
+				_, err := cs.VirtualMachine.StopVirtualMachine(
+					cs.VirtualMachine.NewStopVirtualMachineParams(vm.Id))
+				if err != nil {
+					return fmt.Errorf("Error stopping source instance %s: %s", vm.Name, err)
+				}
+				vmid = vm.Id
+			}
 		}
+	}
 
-		if time.Now().Unix()-currentTime > timeout {
-			return fmt.Errorf("Timeout while waiting for template to become ready")
+	if snapshotid, ok := d.GetOk("source_snapshot_id"); ok {
+		s, _, err := cs.Snapshot.GetSnapshotByID(
+			snapshotid.(string),
+			cloudstack.WithProject(d.Get("project").(string)),
+		)
+		if err != nil {
+			return fmt.Errorf("Error retrieving source snapshot %s: %s", snapshotid.(string), err)
+		}
+
+		p.SetSnapshotid(s.Id)
+		tags = s.Tags
+
+		// Retrieve the zone ID
+		if v, ok := d.GetOk("zone"); ok {
+			zoneid, e := retrieveID(cs, "zone", v.(string))
+			if e != nil {
+				return e.Error()
+			}
+			p.SetZoneid(zoneid)
 		}
 	}
-}
 
-func resourceCloudStackTemplateRead(d *schema.ResourceData, meta interface{}) error {
-	cs := meta.(*cloudstack.CloudStackClient)
+	// Create the new template
+	r, err := cs.Template.CreateTemplate(p)
+	if err == nil {
+		d.SetId(r.Id)
+	}
 
-	// Get the template details
-	p := cs.Template.NewListTemplatesParams("executable")
-	p.SetId(d.Id())
-	p.SetShowunique(true)
-	project := d.Get("project").(string)
-	if project != "" {
-		if !cloudstack.IsID(project) {
-			id, _, err := cs.Project.GetProjectID(project)
-			if err != nil {
-				return err
+	// Start the instance again if we stopped it, also when the capture failed
+	if vmid != "" {
+		log.Printf("[DEBUG] Starting instance %s after capturing template %s", vmid, name)
+		_, serr := cs.VirtualMachine.StartVirtualMachine(
+			cs.VirtualMachine.NewStartVirtualMachineParams(vmid))
+		if serr != nil {
+			if err == nil {
+				return fmt.Errorf(
+					"Error starting source instance %s after capturing template %s: %s", vmid, name, serr)
 			}
-			project = id
+			log.Printf("[WARN] Error starting source instance %s: %s", vmid, serr)
 		}
-		p.SetProjectid(project)
 	}
 
-	r, err := cs.Template.ListTemplates(p)
+	if err != nil {
+		return fmt.Errorf("Error creating template %s: %s", name, err)
+	}
+
+	// Copy the tags of the source, or set the configured tags
+	if d.Get("copy_source_tags").(bool) && len(tags) > 0 {
+		t := make(map[string]interface{}, len(tags))
+		for _, tag := range tags {
+			t[tag.Key] = tag.Value
+		}
+		d.Set("tags", t)
+	}
+
+	if err = setTags(cs, d, "Template"); err != nil {
+		return fmt.Errorf("Error setting tags on the template %s: %s", name, err)
+	}
+
+	// Wait until the template is ready to use, or timeout with an error...
+	return waitForImageReady(d, meta, "Template", resourceCloudStackTemplateRead)
+}
+
+func resourceCloudStackTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Get the template details for all zones
+	templates, err := listTemplateZones(cs, d)
 	if err != nil {
 		return err
-	} else if r.Count == 0 {
+	}
+
+	if len(templates) == 0 {
 		log.Printf(
 			"[DEBUG] Template %s no longer exists", d.Get("name").(string))
 		d.SetId("")
 		return nil
 	}
 
-	t := r.Templates[0]
+	// Retrieve the IDs of the zones we have to wait for
+	var zoneids []string
+	if v, ok := d.GetOk("zones"); ok {
+		for _, zone := range v.(*schema.Set).List() {
+			zoneid, e := retrieveID(cs, "zone", zone.(string))
+			if e != nil {
+				return e.Error()
+			}
+			zoneids = append(zoneids, zoneid)
+		}
+	}
+	if v, ok := d.GetOk("zone"); ok && !isAllZones(v.(string)) {
+		zoneid, e := retrieveID(cs, "zone", v.(string))
+		if e != nil {
+			return e.Error()
+		}
+		zoneids = append(zoneids, zoneid)
+	}
+
+	t := templates[0]
+	for _, template := range templates {
+		if len(zoneids) == 1 && template.Zoneid == zoneids[0] {
+			t = template
+		}
+	}
 
 	d.Set("name", t.Name)
 	d.Set("display_text", t.Displaytext)
@@ -276,7 +605,25 @@ func resourceCloudStackTemplateRead(d *schema.ResourceData, meta interface{}) er
 	d.Set("is_featured", t.Isfeatured)
 	d.Set("is_public", t.Ispublic)
 	d.Set("password_enabled", t.Passwordenabled)
-	d.Set("is_ready", t.Isready)
+	d.Set("requires_hvm", t.Requireshvm)
+	d.Set("ssh_key_enabled", t.Sshkeyenabled)
+	d.Set("checksum", t.Checksum)
+	d.Set("direct_download", t.Directdownload)
+	d.Set("deploy_as_is", t.Deployasis)
+	d.Set("template_tag", t.Templatetag)
+	d.Set("boot_mode", t.Details[uefiDetail])
+
+	// Only read back the details we manage, as CloudStack adds details
+	// of its own to the template
+	details := make(map[string]interface{})
+	for k := range d.Get("details").(map[string]interface{}) {
+		if v, ok := t.Details[k]; ok {
+			details[k] = v
+		}
+	}
+	d.Set("details", details)
+
+	d.Set("is_ready", setImageZoneStatus(d, templateZones(templates), zoneids))
 
 	tags := make(map[string]interface{})
 	for _, tag := range t.Tags {
@@ -286,7 +633,10 @@ func resourceCloudStackTemplateRead(d *schema.ResourceData, meta interface{}) er
 
 	setValueOrID(d, "os_type", t.Ostypename, t.Ostypeid)
 	setValueOrID(d, "project", t.Project, t.Projectid)
-	setValueOrID(d, "zone", t.Zonename, t.Zoneid)
+
+	if v, ok := d.GetOk("zone"); ok && !isAllZones(v.(string)) {
+		setValueOrID(d, "zone", t.Zonename, t.Zoneid)
+	}
 
 	return nil
 }
@@ -326,6 +676,42 @@ func resourceCloudStackTemplateUpdate(d *schema.ResourceData, meta interface{})
 		p.SetPasswordenabled(d.Get("password_enabled").(bool))
 	}
 
+	if d.HasChange("requires_hvm") {
+		p.SetRequireshvm(d.Get("requires_hvm").(bool))
+	}
+
+	if d.HasChange("ssh_key_enabled") {
+		p.SetSshkeyenabled(d.Get("ssh_key_enabled").(bool))
+	}
+
+	if d.HasChange("template_tag") {
+		p.SetTemplatetag(d.Get("template_tag").(string))
+	}
+
+	// The details are always replaced as a whole, so merge the managed details
+	// into the details CloudStack added to the template itself
+	if d.HasChange("details") || d.HasChange("boot_mode") {
+		templates, err := listTemplateZones(cs, d)
+		if err != nil {
+			return err
+		}
+
+		var current map[string]string
+		if len(templates) > 0 {
+			current = templates[0].Details
+		}
+
+		od, _ := d.GetChange("details")
+		ob, _ := d.GetChange("boot_mode")
+		managed := managedTemplateDetails(od.(map[string]interface{}), ob.(string))
+
+		if details := mergeTemplateDetails(current, managed, templateDetails(d)); len(details) > 0 {
+			p.SetDetails(details)
+		} else {
+			p.SetCleanupdetails(true)
+		}
+	}
+
 	_, err := cs.Template.UpdateTemplate(p)
 	if err != nil {
 		return fmt.Errorf("Error updating template %s: %s", name, err)
@@ -362,12 +748,346 @@ func resourceCloudStackTemplateDelete(d *schema.ResourceData, meta interface{})
 	return nil
 }
 
+// templateParams is implemented by the parameter structs of all APIs that
+// create a template, so the common parameters can be set in one place.
+type templateParams interface {
+	SetOstypeid(string)
+	SetIsdynamicallyscalable(bool)
+	SetIsextractable(bool)
+	SetIsfeatured(bool)
+	SetIspublic(bool)
+	SetPasswordenabled(bool)
+	SetRequireshvm(bool)
+	SetSshkeyenabled(bool)
+	SetDeployasis(bool)
+	SetTemplatetag(string)
+	SetDetails(map[string]string)
+}
+
+func setTemplateParams(cs *cloudstack.CloudStackClient, d *schema.ResourceData, p templateParams) {
+	// Retrieve the os_type ID
+	ostypeid, e := retrieveID(cs, "os_type", d.Get("os_type").(string))
+	if e == nil {
+		p.SetOstypeid(ostypeid)
+	}
+
+	// Set optional parameters
+	if v, ok := d.GetOk("is_dynamically_scalable"); ok {
+		p.SetIsdynamicallyscalable(v.(bool))
+	}
+
+	if v, ok := d.GetOk("is_extractable"); ok {
+		p.SetIsextractable(v.(bool))
+	}
+
+	if v, ok := d.GetOk("is_featured"); ok {
+		p.SetIsfeatured(v.(bool))
+	}
+
+	if v, ok := d.GetOk("is_public"); ok {
+		p.SetIspublic(v.(bool))
+	}
+
+	if v, ok := d.GetOk("password_enabled"); ok {
+		p.SetPasswordenabled(v.(bool))
+	}
+
+	if v, ok := d.GetOk("requires_hvm"); ok {
+		p.SetRequireshvm(v.(bool))
+	}
+
+	if v, ok := d.GetOk("ssh_key_enabled"); ok {
+		p.SetSshkeyenabled(v.(bool))
+	}
+
+	if v, ok := d.GetOk("deploy_as_is"); ok {
+		p.SetDeployasis(v.(bool))
+	}
+
+	if v, ok := d.GetOk("template_tag"); ok {
+		p.SetTemplatetag(v.(string))
+	}
+
+	if details := templateDetails(d); len(details) > 0 {
+		p.SetDetails(details)
+	}
+}
+
+// uefiDetail is the template detail CloudStack uses to mark a template as
+// requiring UEFI, with the boot mode as its value.
+const uefiDetail = "UEFI"
+
+// templateDetails returns the configured details of the template, including
+// the detail that sets the boot mode.
+func templateDetails(d *schema.ResourceData) map[string]string {
+	return managedTemplateDetails(d.Get("details").(map[string]interface{}), d.Get("boot_mode").(string))
+}
+
+// managedTemplateDetails returns the given details, including the detail that
+// sets the given boot mode.
+func managedTemplateDetails(configured map[string]interface{}, bootMode string) map[string]string {
+	details := make(map[string]string)
+	for k, v := range configured {
+		details[k] = v.(string)
+	}
+
+	if bootMode != "" {
+		details[uefiDetail] = bootMode
+	}
+
+	return details
+}
+
+// mergeTemplateDetails returns the current details of a template with the
+// previously managed details replaced by the wanted details. Details that are
+// not managed by the resource are kept as is.
+func mergeTemplateDetails(current, managed, wanted map[string]string) map[string]string {
+	details := make(map[string]string, len(current)+len(wanted))
+	for k, v := range current {
+		if _, ok := managed[k]; !ok {
+			details[k] = v
+		}
+	}
+
+	for k, v := range wanted {
+		details[k] = v
+	}
+
+	return details
+}
+
 func verifyTemplateParams(d *schema.ResourceData) error {
+	_, volume := d.GetOk("source_volume_id")
+	_, snapshot := d.GetOk("source_snapshot_id")
+
+	// The format and hypervisor of captured templates follow from the source
+	if volume || snapshot {
+		for _, k := range []string{"is_extractable", "checksum", "direct_download", "deploy_as_is"} {
+			if _, ok := d.GetOk(k); ok {
+				return fmt.Errorf(
+					"%s is not supported for templates created from a volume or snapshot", k)
+			}
+		}
+		if volume {
+			if _, ok := d.GetOk("zone"); ok {
+				return fmt.Errorf(
+					"A template created from a volume is always created in the zone of the volume")
+			}
+		}
+		return nil
+	}
+
+	if _, ok := d.GetOk("hypervisor"); !ok {
+		return fmt.Errorf("A hypervisor is required to register or upload a template")
+	}
+
 	format := d.Get("format").(string)
 	if format != "OVA" && format != "QCOW2" && format != "RAW" && format != "VHD" && format != "VMDK" {
 		return fmt.Errorf(
 			"%s is not a valid format. Valid options are 'OVA','QCOW2', 'RAW', 'VHD' and 'VMDK'", format)
 	}
 
+	if _, ok := d.GetOk("source_file"); ok {
+		if _, ok := d.GetOk("direct_download"); ok {
+			return fmt.Errorf("direct_download is only supported for templates registered from a url")
+		}
+		if zone := d.Get("zone").(string); zone == "" || isAllZones(zone) {
+			return fmt.Errorf(
+				"A single zone is required when uploading a template from a source_file; " +
+					"use cloudstack_template_copy to make it available in other zones")
+		}
+	}
+
 	return nil
 }
+
+// isAllZones returns true if the zone refers to all zones.
+func isAllZones(zone string) bool {
+	return zone == "all" || zone == "-1"
+}
+
+func imageZoneStatusSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"zone_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+
+				"zone_name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+
+				"is_ready": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+
+				"status": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// listTemplateZones returns the template with the ID of the resource once
+// for every zone it is available in.
+func listTemplateZones(cs *cloudstack.CloudStackClient, d *schema.ResourceData) ([]*cloudstack.Template, error) {
+	p := cs.Template.NewListTemplatesParams("executable")
+	p.SetId(d.Id())
+
+	project := d.Get("project").(string)
+	if project != "" {
+		if !cloudstack.IsID(project) {
+			id, _, err := cs.Project.GetProjectID(project)
+			if err != nil {
+				return nil, err
+			}
+			project = id
+		}
+		p.SetProjectid(project)
+	}
+
+	r, err := cs.Template.ListTemplates(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Templates, nil
+}
+
+// imageZone holds the state of a template or ISO in a single zone.
+type imageZone struct {
+	zoneid   string
+	zonename string
+	isready  bool
+	status   string
+}
+
+func templateZones(templates []*cloudstack.Template) []imageZone {
+	zones := make([]imageZone, 0, len(templates))
+	for _, t := range templates {
+		zones = append(zones, imageZone{t.Zoneid, t.Zonename, t.Isready, t.Status})
+	}
+	return zones
+}
+
+// setImageZoneStatus sets the readiness of the template or ISO in each zone
+// it is available in, and returns true if it is ready in all of the given
+// zones. If no zones are given, it needs to be ready in all zones it is
+// available in.
+func setImageZoneStatus(d *schema.ResourceData, zones []imageZone, zoneids []string) bool {
+	status := make([]interface{}, 0, len(zones))
+	ready := make(map[string]bool, len(zones))
+
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].zonename < zones[j].zonename
+	})
+
+	for _, z := range zones {
+		status = append(status, map[string]interface{}{
+			"zone_id":   z.zoneid,
+			"zone_name": z.zonename,
+			"is_ready":  z.isready,
+			"status":    z.status,
+		})
+		ready[z.zoneid] = z.isready
+	}
+	d.Set("zone_status", status)
+
+	if len(zoneids) == 0 {
+		for _, isReady := range ready {
+			if !isReady {
+				return false
+			}
+		}
+		return len(ready) > 0
+	}
+
+	for _, zoneid := range zoneids {
+		if !ready[zoneid] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// imageReadyInZones returns true if the zone status shows the template or ISO
+// is ready in all of the given zones.
+func imageReadyInZones(status []interface{}, zoneids []string) bool {
+	ready := make(map[string]bool, len(status))
+	for _, s := range status {
+		s := s.(map[string]interface{})
+		ready[s["zone_id"].(string)] = s["is_ready"].(bool)
+	}
+
+	for _, zoneid := range zoneids {
+		if !ready[zoneid] {
+			return false
+		}
+	}
+
+	return len(zoneids) > 0
+}
+
+// waitForImageReady keeps reading the template or ISO until it is ready in
+// all zones, or times out with an error. If zone IDs are given, it only waits
+// until it is ready in those zones.
+func waitForImageReady(d *schema.ResourceData, meta interface{}, kind string, read schema.ReadFunc, zoneids ...string) error {
+	currentTime := time.Now().Unix()
+	timeout := int64(d.Get("is_ready_timeout").(int))
+	for {
+		// Start with the sleep so the register action has a few seconds
+		// to process the registration correctly. Without this wait
+		time.Sleep(10 * time.Second)
+
+		err := read(d, meta)
+		if err != nil {
+			return err
+		}
+
+		if d.Id() == "" {
+			return fmt.Errorf("%s disappeared while waiting for it to become ready", kind)
+		}
+
+		if len(zoneids) > 0 {
+			if imageReadyInZones(d.Get("zone_status").([]interface{}), zoneids) {
+				return nil
+			}
+		} else if d.Get("is_ready").(bool) {
+			return nil
+		}
+
+		// Stop waiting if the download or upload failed in any of the zones,
+		// for example because the checksum of the image didn't match
+		for _, s := range d.Get("zone_status").([]interface{}) {
+			s := s.(map[string]interface{})
+			if strings.Contains(strings.ToLower(s["status"].(string)), "error") {
+				return fmt.Errorf(
+					"%s failed to become ready in zone %s: %s", kind, s["zone_name"], s["status"])
+			}
+		}
+
+		if time.Now().Unix()-currentTime > timeout {
+			var pending []string
+			for _, s := range d.Get("zone_status").([]interface{}) {
+				s := s.(map[string]interface{})
+				if !s["is_ready"].(bool) {
+					pending = append(pending, fmt.Sprintf("%s (%s)", s["zone_name"], s["status"]))
+				}
+			}
+			if len(pending) == 0 {
+				return fmt.Errorf("Timeout while waiting for %s to become ready", strings.ToLower(kind))
+			}
+			return fmt.Errorf(
+				"Timeout while waiting for %s to become ready in zone(s): %s", strings.ToLower(kind), strings.Join(pending, ", "))
+		}
+	}
+}
@@ -0,0 +1,243 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceCloudStackTemplateCopy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCloudStackTemplateCopyCreate,
+		Read:   resourceCloudStackTemplateCopyRead,
+		Update: resourceCloudStackTemplateCopyUpdate,
+		Delete: resourceCloudStackTemplateCopyDelete,
+
+		Schema: map[string]*schema.Schema{
+			"template_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"source_zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"zones": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"is_ready": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"is_ready_timeout": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  300,
+			},
+
+			"zone_status": imageZoneStatusSchema(),
+		},
+	}
+}
+
+func resourceCloudStackTemplateCopyCreate(d *schema.ResourceData, meta interface{}) error {
+	d.SetId(d.Get("template_id").(string))
+
+	if err := copyTemplateToZones(d, meta, d.Get("zones").(*schema.Set)); err != nil {
+		// Make sure we never delete the template from any zone when the copy failed
+		d.SetId("")
+		return err
+	}
+
+	// Wait until the template is ready to use in all zones, or timeout with an error...
+	return waitForImageReady(d, meta, "Template", resourceCloudStackTemplateCopyRead)
+}
+
+func copyTemplateToZones(d *schema.ResourceData, meta interface{}, zones *schema.Set) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	templates, err := listTemplateZones(cs, d)
+	if err != nil {
+		return err
+	}
+
+	available := make(map[string]bool, len(templates))
+	for _, t := range templates {
+		available[t.Zoneid] = true
+	}
+
+	var zoneids []string
+	for _, zone := range zones.List() {
+		zoneid, e := retrieveID(cs, "zone", zone.(string))
+		if e != nil {
+			return e.Error()
+		}
+
+		// Deleting this resource removes the template from all its zones, so
+		// we should never take ownership of an existing copy of the template
+		if available[zoneid] {
+			return fmt.Errorf(
+				"Template %s is already available in zone %s", d.Id(), zone.(string))
+		}
+
+		zoneids = append(zoneids, zoneid)
+	}
+
+	// Create a new parameter struct
+	p := cs.Template.NewCopyTemplateParams(d.Id())
+	p.SetDestzoneids(zoneids)
+
+	if v, ok := d.GetOk("source_zone"); ok {
+		zoneid, e := retrieveID(cs, "zone", v.(string))
+		if e != nil {
+			return e.Error()
+		}
+		p.SetSourcezoneid(zoneid)
+	}
+
+	log.Printf("[DEBUG] Copying template %s to zone(s): %s", d.Id(), strings.Join(zoneids, ", "))
+	if _, err := cs.Template.CopyTemplate(p); err != nil {
+		return fmt.Errorf("Error copying template %s: %s", d.Id(), err)
+	}
+
+	return nil
+}
+
+func resourceCloudStackTemplateCopyRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Get the template details for all zones
+	templates, err := listTemplateZones(cs, d)
+	if err != nil {
+		return err
+	}
+
+	if len(templates) == 0 {
+		log.Printf("[DEBUG] Template %s no longer exists", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	available := make(map[string]bool, len(templates))
+	for _, t := range templates {
+		available[t.Zoneid] = true
+	}
+
+	// Only keep the zones the template is still available in, so the
+	// template is copied again to any zone it was removed from
+	var zoneids []string
+	zones := &schema.Set{F: schema.HashString}
+	for _, zone := range d.Get("zones").(*schema.Set).List() {
+		zoneid, e := retrieveID(cs, "zone", zone.(string))
+		if e != nil {
+			return e.Error()
+		}
+		zoneids = append(zoneids, zoneid)
+
+		if available[zoneid] {
+			zones.Add(zone)
+		}
+	}
+
+	d.Set("zones", zones)
+	d.Set("is_ready", setImageZoneStatus(d, templateZones(templates), zoneids))
+
+	return nil
+}
+
+func resourceCloudStackTemplateCopyUpdate(d *schema.ResourceData, meta interface{}) error {
+	if d.HasChange("zones") {
+		o, n := d.GetChange("zones")
+		ozs := o.(*schema.Set).Difference(n.(*schema.Set))
+		nzs := n.(*schema.Set).Difference(o.(*schema.Set))
+
+		if ozs.Len() > 0 {
+			if err := deleteTemplateFromZones(d, meta, ozs); err != nil {
+				return err
+			}
+		}
+
+		if nzs.Len() > 0 {
+			if err := copyTemplateToZones(d, meta, nzs); err != nil {
+				return err
+			}
+
+			return waitForImageReady(d, meta, "Template", resourceCloudStackTemplateCopyRead)
+		}
+	}
+
+	return resourceCloudStackTemplateCopyRead(d, meta)
+}
+
+func resourceCloudStackTemplateCopyDelete(d *schema.ResourceData, meta interface{}) error {
+	return deleteTemplateFromZones(d, meta, d.Get("zones").(*schema.Set))
+}
+
+func deleteTemplateFromZones(d *schema.ResourceData, meta interface{}, zones *schema.Set) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	for _, zone := range zones.List() {
+		zoneid, e := retrieveID(cs, "zone", zone.(string))
+		if e != nil {
+			return e.Error()
+		}
+
+		// Create a new parameter struct
+		p := cs.Template.NewDeleteTemplateParams(d.Id())
+		p.SetZoneid(zoneid)
+
+		// Delete the template from the zone
+		log.Printf("[INFO] Deleting template %s from zone %s", d.Id(), zone.(string))
+		_, err := cs.Template.DeleteTemplate(p)
+		if err != nil {
+			// This is a very poor way to be told the ID does no longer exist :(
+			if strings.Contains(err.Error(), fmt.Sprintf(
+				"Invalid parameter id value=%s due to incorrect long value format, "+
+					"or entity does not exist", d.Id())) {
+				continue
+			}
+
+			return fmt.Errorf("Error deleting template %s from zone %s: %s", d.Id(), zone.(string), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccCloudStackTemplateCopy_basic(t *testing.T) {
+	if cloudStackTemplateURL == "" || cloudStackSecondZone == "" {
+		t.Skip("This test requires an upload URL and a second zone")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackTemplateCopyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackTemplateCopy_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplateCopyExists("cloudstack_template_copy.foo"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_copy.foo", "is_ready", "true"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_copy.foo", "zones.#", "1"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_copy.foo", "zone_status.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckCloudStackTemplateCopyExists(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No template ID is set")
+		}
+
+		cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+		zoneid, _, err := cs.Zone.GetZoneID(cloudStackSecondZone)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = cs.Template.GetTemplateByID(rs.Primary.ID, "executable", cloudstack.WithZone(zoneid))
+		if err != nil {
+			return fmt.Errorf("Template %s not found in zone %s: %s", rs.Primary.ID, cloudStackSecondZone, err)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackTemplateCopyDestroy(s *terraform.State) error {
+	cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "cloudstack_template_copy" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No template ID is set")
+		}
+
+		zoneid, _, err := cs.Zone.GetZoneID(cloudStackSecondZone)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = cs.Template.GetTemplateByID(rs.Primary.ID, "executable", cloudstack.WithZone(zoneid))
+		if err == nil {
+			return fmt.Errorf("Template %s still exists in zone %s", rs.Primary.ID, cloudStackSecondZone)
+		}
+	}
+
+	return nil
+}
+
+var testAccCloudStackTemplateCopy_basic = fmt.Sprintf(`
+resource "cloudstack_template" "foo" {
+  name = "terraform-test"
+  format = "VHD"
+  hypervisor = "Simulator"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_template_copy" "foo" {
+  template_id = cloudstack_template.foo.id
+  source_zone = "Sandbox-simulator"
+  zones = ["%s"]
+}`, cloudStackTemplateURL, cloudStackSecondZone)
@@ -0,0 +1,287 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceCloudStackTemplatePermissions() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCloudStackTemplatePermissionsCreate,
+		Read:   resourceCloudStackTemplatePermissionsRead,
+		Update: resourceCloudStackTemplatePermissionsUpdate,
+		Delete: resourceCloudStackTemplatePermissionsDelete,
+		Importer: &schema.ResourceImporter{
+			State: importStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"template_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"accounts": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"projects": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"is_public": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"is_featured": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"is_extractable": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceCloudStackTemplatePermissionsCreate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+	d.SetId(d.Get("template_id").(string))
+
+	// Remove any existing permissions, as this resource is authoritative
+	p := cs.Template.NewUpdateTemplatePermissionsParams(d.Id())
+	p.SetOp("reset")
+	p.SetIspublic(d.Get("is_public").(bool))
+	p.SetIsfeatured(d.Get("is_featured").(bool))
+	p.SetIsextractable(d.Get("is_extractable").(bool))
+
+	log.Printf("[DEBUG] Resetting permissions of template %s", d.Id())
+	if _, err := cs.Template.UpdateTemplatePermissions(p); err != nil {
+		d.SetId("")
+		return fmt.Errorf("Error setting permissions of template %s: %s", d.Get("template_id").(string), err)
+	}
+
+	accounts := d.Get("accounts").(*schema.Set)
+	if err := updateTemplateAccounts(cs, d, "add", accounts); err != nil {
+		return err
+	}
+
+	projects := d.Get("projects").(*schema.Set)
+	if err := updateTemplateProjects(cs, d, "add", projects); err != nil {
+		return err
+	}
+
+	return resourceCloudStackTemplatePermissionsRead(d, meta)
+}
+
+func resourceCloudStackTemplatePermissionsRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Get the template details
+	templates, err := listTemplateZones(cs, d)
+	if err != nil {
+		return err
+	}
+
+	if len(templates) == 0 {
+		log.Printf("[DEBUG] Template %s no longer exists", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	t := templates[0]
+
+	// Get the template permissions
+	tp, _, err := cs.Template.GetTemplatePermissionByID(
+		d.Id(),
+		cloudstack.WithProject(d.Get("project").(string)),
+	)
+	if err != nil {
+		return fmt.Errorf("Error retrieving permissions of template %s: %s", d.Id(), err)
+	}
+
+	d.Set("template_id", d.Id())
+	d.Set("is_public", tp.Ispublic)
+	d.Set("is_featured", t.Isfeatured)
+	d.Set("is_extractable", t.Isextractable)
+
+	accounts := &schema.Set{F: schema.HashString}
+	for _, account := range tp.Account {
+		accounts.Add(account)
+	}
+	d.Set("accounts", accounts)
+
+	// Keep the configured name of a project if it matches the project ID
+	configured := make(map[string]string)
+	for _, project := range d.Get("projects").(*schema.Set).List() {
+		projectid, e := retrieveID(cs, "project", project.(string))
+		if e != nil {
+			return e.Error()
+		}
+		configured[projectid] = project.(string)
+	}
+
+	projects := &schema.Set{F: schema.HashString}
+	for _, projectid := range tp.Projectids {
+		if project, ok := configured[projectid]; ok {
+			projects.Add(project)
+		} else {
+			projects.Add(projectid)
+		}
+	}
+	d.Set("projects", projects)
+
+	return nil
+}
+
+func resourceCloudStackTemplatePermissionsUpdate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if d.HasChanges("is_public", "is_featured", "is_extractable") {
+		p := cs.Template.NewUpdateTemplatePermissionsParams(d.Id())
+		p.SetIspublic(d.Get("is_public").(bool))
+		p.SetIsfeatured(d.Get("is_featured").(bool))
+		p.SetIsextractable(d.Get("is_extractable").(bool))
+
+		if _, err := cs.Template.UpdateTemplatePermissions(p); err != nil {
+			return fmt.Errorf("Error updating permissions of template %s: %s", d.Id(), err)
+		}
+	}
+
+	// The old values are read from CloudStack, so any permissions that were
+	// added or removed outside of Terraform are reconciled as well
+	if d.HasChange("accounts") {
+		o, n := d.GetChange("accounts")
+		ors := o.(*schema.Set).Difference(n.(*schema.Set))
+		nrs := n.(*schema.Set).Difference(o.(*schema.Set))
+
+		if err := updateTemplateAccounts(cs, d, "remove", ors); err != nil {
+			return err
+		}
+		if err := updateTemplateAccounts(cs, d, "add", nrs); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("projects") {
+		o, n := d.GetChange("projects")
+		ors := o.(*schema.Set).Difference(n.(*schema.Set))
+		nrs := n.(*schema.Set).Difference(o.(*schema.Set))
+
+		if err := updateTemplateProjects(cs, d, "remove", ors); err != nil {
+			return err
+		}
+		if err := updateTemplateProjects(cs, d, "add", nrs); err != nil {
+			return err
+		}
+	}
+
+	return resourceCloudStackTemplatePermissionsRead(d, meta)
+}
+
+func resourceCloudStackTemplatePermissionsDelete(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Revoke all permissions and make the template private again
+	p := cs.Template.NewUpdateTemplatePermissionsParams(d.Id())
+	p.SetOp("reset")
+	p.SetIspublic(false)
+	p.SetIsfeatured(false)
+	p.SetIsextractable(false)
+
+	log.Printf("[INFO] Resetting permissions of template %s", d.Id())
+	if _, err := cs.Template.UpdateTemplatePermissions(p); err != nil {
+		return fmt.Errorf("Error resetting permissions of template %s: %s", d.Id(), err)
+	}
+
+	return nil
+}
+
+func updateTemplateAccounts(cs *cloudstack.CloudStackClient, d *schema.ResourceData, op string, accounts *schema.Set) error {
+	if accounts.Len() == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, account := range accounts.List() {
+		names = append(names, account.(string))
+	}
+
+	p := cs.Template.NewUpdateTemplatePermissionsParams(d.Id())
+	p.SetOp(op)
+	p.SetAccounts(names)
+
+	if _, err := cs.Template.UpdateTemplatePermissions(p); err != nil {
+		return fmt.Errorf(
+			"Error updating account permissions of template %s: %s", d.Id(), err)
+	}
+
+	return nil
+}
+
+func updateTemplateProjects(cs *cloudstack.CloudStackClient, d *schema.ResourceData, op string, projects *schema.Set) error {
+	if projects.Len() == 0 {
+		return nil
+	}
+
+	var projectids []string
+	for _, project := range projects.List() {
+		projectid, e := retrieveID(cs, "project", project.(string))
+		if e != nil {
+			return e.Error()
+		}
+		projectids = append(projectids, projectid)
+	}
+
+	p := cs.Template.NewUpdateTemplatePermissionsParams(d.Id())
+	p.SetOp(op)
+	p.SetProjectids(projectids)
+
+	if _, err := cs.Template.UpdateTemplatePermissions(p); err != nil {
+		return fmt.Errorf(
+			"Error updating project permissions of template %s: %s", d.Id(), err)
+	}
+
+	return nil
+}
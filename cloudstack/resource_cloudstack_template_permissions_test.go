@@ -0,0 +1,199 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccCloudStackTemplatePermissions_basic(t *testing.T) {
+	if cloudStackTemplateURL == "" {
+		t.Skip("This test requires an upload URL")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackTemplatePermissionsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackTemplatePermissions_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplatePermissionsProjects(
+						"cloudstack_template_permissions.foo", 1),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_permissions.foo", "projects.#", "1"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_permissions.foo", "is_public", "false"),
+				),
+			},
+
+			{
+				Config: testAccCloudStackTemplatePermissions_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplatePermissionsProjects(
+						"cloudstack_template_permissions.foo", 2),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_permissions.foo", "projects.#", "2"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_permissions.foo", "is_public", "true"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template_permissions.foo", "is_extractable", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackTemplatePermissions_import(t *testing.T) {
+	if cloudStackTemplateURL == "" {
+		t.Skip("This test requires an upload URL")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackTemplatePermissionsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackTemplatePermissions_basic,
+			},
+
+			{
+				ResourceName:      "cloudstack_template_permissions.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"projects",
+				},
+			},
+		},
+	})
+}
+
+func testAccCheckCloudStackTemplatePermissionsProjects(n string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No template ID is set")
+		}
+
+		cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+		tp, _, err := cs.Template.GetTemplatePermissionByID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(tp.Projectids) != count {
+			return fmt.Errorf(
+				"Template %s is shared with %d project(s), expected %d", rs.Primary.ID, len(tp.Projectids), count)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackTemplatePermissionsDestroy(s *terraform.State) error {
+	cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "cloudstack_template_permissions" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No template ID is set")
+		}
+
+		// The template itself is destroyed as well, so if it still
+		// exists it should no longer be shared
+		tp, _, err := cs.Template.GetTemplatePermissionByID(rs.Primary.ID)
+		if err != nil {
+			continue
+		}
+
+		if tp.Ispublic || len(tp.Account) > 0 || len(tp.Projectids) > 0 {
+			return fmt.Errorf("Template %s is still shared", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+var testAccCloudStackTemplatePermissions_basic = fmt.Sprintf(`
+resource "cloudstack_template" "foo" {
+  name = "terraform-test"
+  format = "VHD"
+  hypervisor = "Simulator"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_project" "foo" {
+  name = "terraform-test-project-1"
+  display_text = "terraform-test-project-1"
+}
+
+resource "cloudstack_project" "bar" {
+  name = "terraform-test-project-2"
+  display_text = "terraform-test-project-2"
+}
+
+resource "cloudstack_template_permissions" "foo" {
+  template_id = cloudstack_template.foo.id
+  projects = [cloudstack_project.foo.name]
+}`, cloudStackTemplateURL)
+
+var testAccCloudStackTemplatePermissions_update = fmt.Sprintf(`
+resource "cloudstack_template" "foo" {
+  name = "terraform-test"
+  format = "VHD"
+  hypervisor = "Simulator"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_project" "foo" {
+  name = "terraform-test-project-1"
+  display_text = "terraform-test-project-1"
+}
+
+resource "cloudstack_project" "bar" {
+  name = "terraform-test-project-2"
+  display_text = "terraform-test-project-2"
+}
+
+resource "cloudstack_template_permissions" "foo" {
+  template_id = cloudstack_template.foo.id
+  projects = [cloudstack_project.foo.name, cloudstack_project.bar.id]
+  is_public = true
+  is_extractable = true
+}`, cloudStackTemplateURL)
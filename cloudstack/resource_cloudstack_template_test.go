@@ -21,6 +21,7 @@ package cloudstack
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
@@ -88,6 +89,171 @@ func TestAccCloudStackTemplate_update(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackTemplate_zones(t *testing.T) {
+	if cloudStackTemplateURL == "" {
+		t.Skip("This test requires an upload URL")
+	}
+
+	var template cloudstack.Template
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackTemplate_zones,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplateExists("cloudstack_template.foo", &template),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "is_ready", "true"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "zone_status.#", "1"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "zone_status.0.zone_name", "Sandbox-simulator"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "zone_status.0.is_ready", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackTemplate_sourceFile(t *testing.T) {
+	if cloudStackTemplateFile == "" {
+		t.Skip("This test requires a local template file")
+	}
+
+	var template cloudstack.Template
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackTemplate_sourceFile,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplateExists("cloudstack_template.foo", &template),
+					testAccCheckCloudStackTemplateBasicAttributes(&template),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "is_ready", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackTemplate_fromVolume(t *testing.T) {
+	var template cloudstack.Template
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackTemplate_fromVolume,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplateExists("cloudstack_template.foo", &template),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "is_ready", "true"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "hypervisor", "Simulator"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "tags.terraform-tag", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackTemplate_details(t *testing.T) {
+	if cloudStackTemplateURL == "" {
+		t.Skip("This test requires an upload URL")
+	}
+
+	var template cloudstack.Template
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackTemplate_details,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplateExists("cloudstack_template.foo", &template),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "boot_mode", "LEGACY"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "details.rootDiskController", "scsi"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "ssh_key_enabled", "true"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "template_tag", "terraform"),
+				),
+			},
+
+			{
+				Config: testAccCloudStackTemplate_detailsUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackTemplateExists("cloudstack_template.foo", &template),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "boot_mode", "SECURE"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "details.rootDiskController", "virtio"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "details.nicAdapter", "E1000"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_template.foo", "ssh_key_enabled", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestMergeTemplateDetails(t *testing.T) {
+	current := map[string]string{
+		"rootDiskController":                 "scsi",
+		"UEFI":                               "LEGACY",
+		"Message.ReservedCapacityFreed.Flag": "false",
+	}
+
+	cases := []struct {
+		Managed  map[string]string
+		Wanted   map[string]string
+		Expected map[string]string
+	}{
+		{
+			map[string]string{"rootDiskController": "scsi", "UEFI": "LEGACY"},
+			map[string]string{"rootDiskController": "virtio"},
+			map[string]string{"rootDiskController": "virtio", "Message.ReservedCapacityFreed.Flag": "false"},
+		},
+		{
+			map[string]string{"rootDiskController": "scsi", "UEFI": "LEGACY"},
+			map[string]string{},
+			map[string]string{"Message.ReservedCapacityFreed.Flag": "false"},
+		},
+		{
+			map[string]string{},
+			map[string]string{"nicAdapter": "e1000"},
+			map[string]string{
+				"rootDiskController":                 "scsi",
+				"UEFI":                               "LEGACY",
+				"nicAdapter":                         "e1000",
+				"Message.ReservedCapacityFreed.Flag": "false",
+			},
+		},
+	}
+
+	for i, c := range cases {
+		if details := mergeTemplateDetails(current, c.Managed, c.Wanted); !reflect.DeepEqual(details, c.Expected) {
+			t.Fatalf("case %d: expected %v, got %v", i, c.Expected, details)
+		}
+	}
+}
+
 func testAccCheckCloudStackTemplateExists(
 	n string, template *cloudstack.Template) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
@@ -211,3 +377,106 @@ resource "cloudstack_template" "foo" {
   password_enabled = true
   zone = "Sandbox-simulator"
 }`, cloudStackTemplateURL)
+
+var testAccCloudStackTemplate_zones = fmt.Sprintf(`
+resource "cloudstack_template" "foo" {
+  name = "terraform-test"
+  format = "VHD"
+  hypervisor = "Simulator"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zones = ["Sandbox-simulator"]
+}`, cloudStackTemplateURL)
+
+var testAccCloudStackTemplate_sourceFile = fmt.Sprintf(`
+resource "cloudstack_template" "foo" {
+  name = "terraform-test"
+  format = "VHD"
+  hypervisor = "Simulator"
+  os_type = "CentOS 5.6 (64-bit)"
+  source_file = "%s"
+  zone = "Sandbox-simulator"
+}`, cloudStackTemplateFile)
+
+const testAccCloudStackTemplate_fromVolume = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-network"
+  display_text = "terraform-network"
+  cidr = "10.1.1.0/24"
+  network_offering = "DefaultIsolatedNetworkOfferingWithSourceNatService"
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_instance" "foo" {
+  name = "terraform-test"
+  service_offering= "Small Instance"
+  network_id = cloudstack_network.foo.id
+  template = "CentOS 5.6 (64-bit) no GUI (Simulator)"
+  zone = "Sandbox-simulator"
+  expunge = true
+}
+
+data "cloudstack_volume" "root" {
+  filter {
+    name = "virtualmachineid"
+    value = cloudstack_instance.foo.id
+  }
+
+  filter {
+    name = "type"
+    value = "ROOT"
+  }
+}
+
+resource "cloudstack_tags" "root" {
+  resource_ids = [data.cloudstack_volume.root.id]
+  resource_type = "Volume"
+  tags = {
+    terraform-tag = "true"
+  }
+}
+
+resource "cloudstack_template" "foo" {
+  name = "terraform-test-capture"
+  os_type = "CentOS 5.6 (64-bit)"
+  source_volume_id = data.cloudstack_volume.root.id
+  stop_source_instance = true
+  copy_source_tags = true
+
+  depends_on = [
+    cloudstack_tags.root
+  ]
+}`
+
+var testAccCloudStackTemplate_details = fmt.Sprintf(`
+resource "cloudstack_template" "foo" {
+  name = "terraform-test"
+  format = "VHD"
+  hypervisor = "Simulator"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+  ssh_key_enabled = true
+  template_tag = "terraform"
+  boot_mode = "LEGACY"
+  details = {
+    rootDiskController = "scsi"
+  }
+}`, cloudStackTemplateURL)
+
+var testAccCloudStackTemplate_detailsUpdate = fmt.Sprintf(`
+resource "cloudstack_template" "foo" {
+  name = "terraform-test"
+  format = "VHD"
+  hypervisor = "Simulator"
+  os_type = "CentOS 5.6 (64-bit)"
+  url = "%s"
+  zone = "Sandbox-simulator"
+  ssh_key_enabled = false
+  template_tag = "terraform"
+  boot_mode = "SECURE"
+  details = {
+    rootDiskController = "virtio"
+    nicAdapter = "E1000"
+  }
+}`, cloudStackTemplateURL)
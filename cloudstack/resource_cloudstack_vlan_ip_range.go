@@ -0,0 +1,383 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceCloudStackVlanIpRange() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCloudStackVlanIpRangeCreate,
+		Read:   resourceCloudStackVlanIpRangeRead,
+		Update: resourceCloudStackVlanIpRangeUpdate,
+		Delete: resourceCloudStackVlanIpRangeDelete,
+		Importer: &schema.ResourceImporter{
+			State: importStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"network_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"physical_network_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"pod_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"vlan": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"gateway": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"netmask": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"start_ip": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"end_ip": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"ip6_cidr": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"ip6_gateway": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"start_ipv6": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"end_ipv6": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"for_virtual_network": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"for_system_vms": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+
+			"account": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"domain_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceCloudStackVlanIpRangeCreate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if err := verifyVlanIpRangeParams(d); err != nil {
+		return err
+	}
+
+	// Create a new parameter struct
+	p := cs.VLAN.NewCreateVlanIpRangeParams()
+
+	if networkid, ok := d.GetOk("network_id"); ok {
+		p.SetNetworkid(networkid.(string))
+	}
+
+	if physicalnetworkid, ok := d.GetOk("physical_network_id"); ok {
+		p.SetPhysicalnetworkid(physicalnetworkid.(string))
+	}
+
+	// Retrieve the zone ID
+	if zone, ok := d.GetOk("zone"); ok {
+		zoneid, e := retrieveID(cs, "zone", zone.(string))
+		if e != nil {
+			return e.Error()
+		}
+		p.SetZoneid(zoneid)
+	}
+
+	if podid, ok := d.GetOk("pod_id"); ok {
+		p.SetPodid(podid.(string))
+	}
+
+	if vlan, ok := d.GetOk("vlan"); ok {
+		p.SetVlan(vlan.(string))
+	}
+
+	// Set the IPv4 range if we have one
+	if gateway, ok := d.GetOk("gateway"); ok {
+		p.SetGateway(gateway.(string))
+	}
+	if netmask, ok := d.GetOk("netmask"); ok {
+		p.SetNetmask(netmask.(string))
+	}
+	if startip, ok := d.GetOk("start_ip"); ok {
+		p.SetStartip(startip.(string))
+	}
+	if endip, ok := d.GetOk("end_ip"); ok {
+		p.SetEndip(endip.(string))
+	}
+
+	// Set the IPv6 range if we have one
+	if ip6cidr, ok := d.GetOk("ip6_cidr"); ok {
+		p.SetIp6cidr(ip6cidr.(string))
+	}
+	if ip6gateway, ok := d.GetOk("ip6_gateway"); ok {
+		p.SetIp6gateway(ip6gateway.(string))
+	}
+	if startipv6, ok := d.GetOk("start_ipv6"); ok {
+		p.SetStartipv6(startipv6.(string))
+	}
+	if endipv6, ok := d.GetOk("end_ipv6"); ok {
+		p.SetEndipv6(endipv6.(string))
+	}
+
+	if forvirtualnetwork, ok := d.GetOk("for_virtual_network"); ok {
+		p.SetForvirtualnetwork(forvirtualnetwork.(bool))
+	}
+
+	if forsystemvms, ok := d.GetOk("for_system_vms"); ok {
+		p.SetForsystemvms(forsystemvms.(bool))
+	}
+
+	if account, ok := d.GetOk("account"); ok {
+		p.SetAccount(account.(string))
+	}
+
+	if domainid, ok := d.GetOk("domain_id"); ok {
+		p.SetDomainid(domainid.(string))
+	}
+
+	// If there is a project supplied, we retrieve and set the project id
+	if err := setProjectid(p, cs, d); err != nil {
+		return err
+	}
+
+	// Create the new VLAN IP range
+	r, err := cs.VLAN.CreateVlanIpRange(p)
+	if err != nil {
+		return fmt.Errorf("Error creating VLAN IP range: %s", err)
+	}
+
+	d.SetId(r.Id)
+
+	return resourceCloudStackVlanIpRangeRead(d, meta)
+}
+
+func resourceCloudStackVlanIpRangeRead(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Get the VLAN IP range details
+	r, count, err := cs.VLAN.GetVlanIpRangeByID(
+		d.Id(),
+		cloudstack.WithProject(d.Get("project").(string)),
+	)
+	if err != nil {
+		if count == 0 {
+			log.Printf("[DEBUG] VLAN IP range %s does no longer exist", d.Id())
+			d.SetId("")
+			return nil
+		}
+
+		return err
+	}
+
+	d.Set("network_id", r.Networkid)
+	d.Set("physical_network_id", r.Physicalnetworkid)
+	d.Set("pod_id", r.Podid)
+	d.Set("vlan", strings.TrimPrefix(r.Vlan, "vlan://"))
+	d.Set("gateway", r.Gateway)
+	d.Set("netmask", r.Netmask)
+	d.Set("start_ip", r.Startip)
+	d.Set("end_ip", r.Endip)
+	d.Set("ip6_cidr", r.Ip6cidr)
+	d.Set("ip6_gateway", r.Ip6gateway)
+	d.Set("start_ipv6", r.Startipv6)
+	d.Set("end_ipv6", r.Endipv6)
+	d.Set("for_virtual_network", r.Forvirtualnetwork)
+	d.Set("for_system_vms", r.Forsystemvms)
+	d.Set("account", r.Account)
+	d.Set("domain_id", r.Domainid)
+
+	setValueOrID(d, "project", r.Project, r.Projectid)
+
+	// The API only returns the zone ID
+	if _, ok := d.GetOk("zone"); !ok || cloudstack.IsID(d.Get("zone").(string)) {
+		d.Set("zone", r.Zoneid)
+	}
+
+	return nil
+}
+
+func resourceCloudStackVlanIpRangeUpdate(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	if err := verifyVlanIpRangeParams(d); err != nil {
+		return err
+	}
+
+	// Create a new parameter struct
+	p := cs.VLAN.NewUpdateVlanIpRangeParams(d.Id())
+
+	if d.HasChange("gateway") {
+		p.SetGateway(d.Get("gateway").(string))
+	}
+
+	if d.HasChange("netmask") {
+		p.SetNetmask(d.Get("netmask").(string))
+	}
+
+	if d.HasChange("start_ip") || d.HasChange("end_ip") {
+		p.SetStartip(d.Get("start_ip").(string))
+		p.SetEndip(d.Get("end_ip").(string))
+	}
+
+	if d.HasChange("ip6_cidr") {
+		p.SetIp6cidr(d.Get("ip6_cidr").(string))
+	}
+
+	if d.HasChange("ip6_gateway") {
+		p.SetIp6gateway(d.Get("ip6_gateway").(string))
+	}
+
+	if d.HasChange("start_ipv6") || d.HasChange("end_ipv6") {
+		p.SetStartipv6(d.Get("start_ipv6").(string))
+		p.SetEndipv6(d.Get("end_ipv6").(string))
+	}
+
+	if d.HasChange("for_system_vms") {
+		p.SetForsystemvms(d.Get("for_system_vms").(bool))
+	}
+
+	// Update the VLAN IP range
+	_, err := cs.VLAN.UpdateVlanIpRange(p)
+	if err != nil {
+		return fmt.Errorf("Error updating VLAN IP range %s: %s", d.Id(), err)
+	}
+
+	return resourceCloudStackVlanIpRangeRead(d, meta)
+}
+
+func resourceCloudStackVlanIpRangeDelete(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Create a new parameter struct
+	p := cs.VLAN.NewDeleteVlanIpRangeParams(d.Id())
+
+	// Delete the VLAN IP range
+	_, err := cs.VLAN.DeleteVlanIpRange(p)
+	if err != nil {
+		// This is a very poor way to be told the ID does no longer exist :(
+		if strings.Contains(err.Error(), fmt.Sprintf(
+			"Invalid parameter id value=%s due to incorrect long value format, "+
+				"or entity does not exist", d.Id())) {
+			return nil
+		}
+
+		return fmt.Errorf("Error deleting VLAN IP range %s: %s", d.Id(), err)
+	}
+
+	return nil
+}
+
+func verifyVlanIpRangeParams(d *schema.ResourceData) error {
+	_, startip := d.GetOk("start_ip")
+	_, startipv6 := d.GetOk("start_ipv6")
+
+	if !startip && !startipv6 {
+		return fmt.Errorf(
+			"You must supply at least an IPv4 range ('start_ip') or an IPv6 range ('start_ipv6')")
+	}
+
+	return nil
+}
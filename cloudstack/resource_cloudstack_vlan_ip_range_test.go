@@ -0,0 +1,193 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccCloudStackVlanIpRange_basic(t *testing.T) {
+	var vlanIPRange cloudstack.VlanIpRange
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackVlanIpRangeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackVlanIpRange_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackVlanIpRangeExists(
+						"cloudstack_vlan_ip_range.foo", &vlanIPRange),
+					testAccCheckCloudStackVlanIpRangeAttributes(&vlanIPRange),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudStackVlanIpRange_update(t *testing.T) {
+	var vlanIPRange cloudstack.VlanIpRange
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackVlanIpRangeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackVlanIpRange_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackVlanIpRangeExists(
+						"cloudstack_vlan_ip_range.foo", &vlanIPRange),
+					testAccCheckCloudStackVlanIpRangeAttributes(&vlanIPRange),
+				),
+			},
+
+			{
+				Config: testAccCloudStackVlanIpRange_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackVlanIpRangeExists(
+						"cloudstack_vlan_ip_range.foo", &vlanIPRange),
+					resource.TestCheckResourceAttr(
+						"cloudstack_vlan_ip_range.foo", "end_ip", "10.10.20.150"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckCloudStackVlanIpRangeExists(
+	n string, vlanIPRange *cloudstack.VlanIpRange) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No VLAN IP range ID is set")
+		}
+
+		cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+		r, _, err := cs.VLAN.GetVlanIpRangeByID(rs.Primary.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if r.Id != rs.Primary.ID {
+			return fmt.Errorf("VLAN IP range not found")
+		}
+
+		*vlanIPRange = *r
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackVlanIpRangeAttributes(
+	vlanIPRange *cloudstack.VlanIpRange) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+
+		if vlanIPRange.Startip != "10.10.20.100" {
+			return fmt.Errorf("Bad start IP: %s", vlanIPRange.Startip)
+		}
+
+		if vlanIPRange.Endip != "10.10.20.120" {
+			return fmt.Errorf("Bad end IP: %s", vlanIPRange.Endip)
+		}
+
+		if vlanIPRange.Gateway != "10.10.20.1" {
+			return fmt.Errorf("Bad gateway: %s", vlanIPRange.Gateway)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckCloudStackVlanIpRangeDestroy(s *terraform.State) error {
+	cs := testAccProvider.Meta().(*cloudstack.CloudStackClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "cloudstack_vlan_ip_range" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No VLAN IP range ID is set")
+		}
+
+		_, _, err := cs.VLAN.GetVlanIpRangeByID(rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("VLAN IP range %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+const testAccCloudStackVlanIpRange_basic = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-shared-network"
+  display_text = "terraform-shared-network"
+  cidr = "10.10.20.0/24"
+  startip = "10.10.20.10"
+  endip = "10.10.20.50"
+  network_offering = "DefaultSharedNetworkOffering"
+  vlan = 1020
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_vlan_ip_range" "foo" {
+  network_id = cloudstack_network.foo.id
+  gateway = "10.10.20.1"
+  netmask = "255.255.255.0"
+  start_ip = "10.10.20.100"
+  end_ip = "10.10.20.120"
+  vlan = "1020"
+  for_virtual_network = false
+}`
+
+const testAccCloudStackVlanIpRange_update = `
+resource "cloudstack_network" "foo" {
+  name = "terraform-shared-network"
+  display_text = "terraform-shared-network"
+  cidr = "10.10.20.0/24"
+  startip = "10.10.20.10"
+  endip = "10.10.20.50"
+  network_offering = "DefaultSharedNetworkOffering"
+  vlan = 1020
+  zone = "Sandbox-simulator"
+}
+
+resource "cloudstack_vlan_ip_range" "foo" {
+  network_id = cloudstack_network.foo.id
+  gateway = "10.10.20.1"
+  netmask = "255.255.255.0"
+  start_ip = "10.10.20.100"
+  end_ip = "10.10.20.150"
+  vlan = "1020"
+  for_virtual_network = false
+}`
@@ -20,6 +20,7 @@
 package cloudstack
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -34,6 +35,9 @@ func resourceCloudStackVPC() *schema.Resource {
 		Read:   resourceCloudStackVPCRead,
 		Update: resourceCloudStackVPCUpdate,
 		Delete: resourceCloudStackVPCDelete,
+
+		CustomizeDiff: resourceCloudStackVPCCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: importStatePassthrough,
 		},
@@ -59,7 +63,12 @@ func resourceCloudStackVPC() *schema.Resource {
 			"vpc_offering": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
+			},
+
+			"tier_network_offerings": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 
 			"network_domain": {
@@ -69,6 +78,20 @@ func resourceCloudStackVPC() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"ip6dns1": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"ip6dns2": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
 			"project": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -81,6 +104,28 @@ func resourceCloudStackVPC() *schema.Resource {
 				Computed: true,
 			},
 
+			"redundant_router": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+
+			"restart_required": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"restart_trigger": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cleanup": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"zone": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -130,6 +175,14 @@ func resourceCloudStackVPCCreate(d *schema.ResourceData, meta interface{}) error
 		p.SetNetworkdomain(networkDomain.(string))
 	}
 
+	// If there are IPv6 DNS servers supplied, make sure to add them to the request
+	if ip6dns1, ok := d.GetOk("ip6dns1"); ok {
+		p.SetIp6dns1(ip6dns1.(string))
+	}
+	if ip6dns2, ok := d.GetOk("ip6dns2"); ok {
+		p.SetIp6dns2(ip6dns2.(string))
+	}
+
 	// If there is a project supplied, we retrieve and set the project id
 	if err := setProjectid(p, cs, d); err != nil {
 		return err
@@ -149,6 +202,13 @@ func resourceCloudStackVPCCreate(d *schema.ResourceData, meta interface{}) error
 		return fmt.Errorf("Error setting tags on the VPC: %s", err)
 	}
 
+	// Make the VPC routers redundant if the offering didn't already do so
+	if d.Get("redundant_router").(bool) && !r.Redundantvpcrouter {
+		if err := restartVPC(d, meta); err != nil {
+			return err
+		}
+	}
+
 	return resourceCloudStackVPCRead(d, meta)
 }
 
@@ -175,6 +235,10 @@ func resourceCloudStackVPCRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("display_text", v.Displaytext)
 	d.Set("cidr", v.Cidr)
 	d.Set("network_domain", v.Networkdomain)
+	d.Set("ip6dns1", v.Ip6dns1)
+	d.Set("ip6dns2", v.Ip6dns2)
+	d.Set("redundant_router", v.Redundantvpcrouter)
+	d.Set("restart_required", v.Restartrequired)
 
 	tags := make(map[string]interface{})
 	for _, tag := range v.Tags {
@@ -258,6 +322,45 @@ func resourceCloudStackVPCUpdate(d *schema.ResourceData, meta interface{}) error
 		}
 	}
 
+	// Check if the VPC offering is changed
+	if d.HasChange("vpc_offering") {
+		// Retrieve the vpc_offering ID
+		vpcofferingid, e := retrieveID(cs, "vpc_offering", d.Get("vpc_offering").(string))
+		if e != nil {
+			return e.Error()
+		}
+
+		// Create a new parameter struct
+		p := cs.VPC.NewMigrateVPCParams(d.Id(), vpcofferingid)
+
+		// Set the network offerings to use for the existing tiers
+		if tiers, ok := d.GetOk("tier_network_offerings"); ok {
+			offerings := make(map[string]string)
+			for networkid, offering := range tiers.(map[string]interface{}) {
+				networkofferingid, e := retrieveID(cs, "network_offering", offering.(string))
+				if e != nil {
+					return e.Error()
+				}
+				offerings[networkid] = networkofferingid
+			}
+			p.SetTiernetworkofferings(offerings)
+		}
+
+		// Migrate the VPC to the new offering
+		_, err := cs.VPC.MigrateVPC(p)
+		if err != nil {
+			return fmt.Errorf(
+				"Error changing the VPC offering of VPC %s: %s", name, err)
+		}
+	}
+
+	// Check if the VPC routers need to be restarted or made redundant
+	if d.HasChange("restart_trigger") || d.HasChange("redundant_router") {
+		if err := restartVPC(d, meta); err != nil {
+			return err
+		}
+	}
+
 	// Check is the tags have changed
 	if d.HasChange("tags") {
 		err := updateTags(cs, d, "Vpc")
@@ -269,6 +372,46 @@ func resourceCloudStackVPCUpdate(d *schema.ResourceData, meta interface{}) error
 	return resourceCloudStackVPCRead(d, meta)
 }
 
+func restartVPC(d *schema.ResourceData, meta interface{}) error {
+	cs := meta.(*cloudstack.CloudStackClient)
+
+	// Create a new parameter struct
+	p := cs.VPC.NewRestartVPCParams(d.Id())
+
+	// Set the cleanup option
+	p.SetCleanup(d.Get("cleanup").(bool))
+
+	// Make the VPC routers redundant if requested
+	if d.HasChange("redundant_router") && d.Get("redundant_router").(bool) {
+		p.SetMakeredundant(true)
+	}
+
+	log.Printf("[DEBUG] Restarting VPC %s", d.Get("name").(string))
+
+	// Restart the VPC
+	_, err := cs.VPC.RestartVPC(p)
+	if err != nil {
+		return fmt.Errorf(
+			"Error restarting VPC %s: %s", d.Get("name").(string), err)
+	}
+
+	return nil
+}
+
+func resourceCloudStackVPCCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// Redundant routers can only be removed by changing the VPC offering
+	if d.Id() != "" && d.HasChange("redundant_router") && !d.HasChange("vpc_offering") {
+		o, n := d.GetChange("redundant_router")
+		if o.(bool) && !n.(bool) {
+			return fmt.Errorf(
+				"Redundant routers of VPC %s can only be removed by changing "+
+					"to a VPC offering without redundant router support", d.Get("name").(string))
+		}
+	}
+
+	return nil
+}
+
 func resourceCloudStackVPCDelete(d *schema.ResourceData, meta interface{}) error {
 	cs := meta.(*cloudstack.CloudStackClient)
 
@@ -51,6 +51,40 @@ func TestAccCloudStackVPC_basic(t *testing.T) {
 	})
 }
 
+func TestAccCloudStackVPC_update(t *testing.T) {
+	var vpc cloudstack.VPC
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCloudStackVPCDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudStackVPC_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackVPCExists(
+						"cloudstack_vpc.foo", &vpc),
+					testAccCheckCloudStackVPCAttributes(&vpc),
+				),
+			},
+
+			{
+				Config: testAccCloudStackVPC_restart,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCloudStackVPCExists(
+						"cloudstack_vpc.foo", &vpc),
+					resource.TestCheckResourceAttr(
+						"cloudstack_vpc.foo", "vpc_offering", "Redundant VPC offering"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_vpc.foo", "redundant_router", "true"),
+					resource.TestCheckResourceAttr(
+						"cloudstack_vpc.foo", "restart_required", "false"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccCloudStackVPC_import(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -156,3 +190,19 @@ resource "cloudstack_vpc" "foo" {
     terraform-tag = "true"
   }
 }`
+
+const testAccCloudStackVPC_restart = `
+resource "cloudstack_vpc" "foo" {
+  name = "terraform-vpc"
+  display_text = "terraform-vpc-text"
+  cidr = "10.0.0.0/8"
+  vpc_offering = "Redundant VPC offering"
+  network_domain = "terraform-domain"
+  zone = "Sandbox-simulator"
+  redundant_router = true
+  restart_trigger = "1"
+  cleanup = true
+  tags = {
+    terraform-tag = "true"
+  }
+}`
@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -128,6 +130,23 @@ func retrieveTemplateID(cs *cloudstack.CloudStackClient, zoneid, value string) (
 	return id, nil
 }
 
+func retrieveISOID(cs *cloudstack.CloudStackClient, zoneid, value string) (id string, e *retrieveError) {
+	// If the supplied value isn't a ID, try to retrieve the ID ourselves
+	if cloudstack.IsID(value) {
+		return value, nil
+	}
+
+	log.Printf("[DEBUG] Retrieving ID of ISO: %s", value)
+
+	// Ignore count, since an error is returned if there is no exact match
+	id, _, err := cs.ISO.GetIsoID(value, "executable", zoneid)
+	if err != nil {
+		return id, &retrieveError{name: "iso", value: value, err: err}
+	}
+
+	return id, nil
+}
+
 // RetryFunc is the function retried n times
 type RetryFunc func() (interface{}, error)
 
@@ -175,6 +194,112 @@ func importStatePassthrough(d *schema.ResourceData, meta interface{}) ([]*schema
 	return []*schema.ResourceData{d}, nil
 }
 
+// rulePort returns the port or port range as used in the configuration
+// and in the uuids map of the rule resources.
+func rulePort(startPort, endPort int) string {
+	if startPort == endPort {
+		return strconv.Itoa(startPort)
+	}
+	return fmt.Sprintf("%d-%d", startPort, endPort)
+}
+
+// pairRules pairs the changed rules of the new rule set with the changed rules
+// of the old rule set that have the same identity, preferring the old rule that
+// shares the most units (the keys of the uuids map). For each pair the new rule
+// takes over the UUIDs of the units it still contains, while the old rule keeps
+// only the UUIDs of the units that are no longer wanted. Creating the new rules
+// and deleting the old rules then only touches the units that actually changed.
+func pairRules(
+	ors *schema.Set,
+	nrs *schema.Set,
+	identity func(map[string]interface{}) string,
+	units func(map[string]interface{}) []string) [][2]map[string]interface{} {
+	var pairs [][2]map[string]interface{}
+
+	olds := ors.List()
+	paired := make(map[int]bool)
+
+	for _, nr := range nrs.List() {
+		nr := nr.(map[string]interface{})
+		wanted := units(nr)
+
+		best, shared := -1, -1
+		for i, or := range olds {
+			or := or.(map[string]interface{})
+			if paired[i] || identity(or) != identity(nr) {
+				continue
+			}
+
+			n := 0
+			for _, unit := range wanted {
+				if _, ok := or["uuids"].(map[string]interface{})[unit]; ok {
+					n++
+				}
+			}
+
+			if n > shared {
+				best, shared = i, n
+			}
+		}
+
+		if best < 0 {
+			continue
+		}
+		paired[best] = true
+
+		or := olds[best].(map[string]interface{})
+
+		// Split the UUIDs in the ones to keep and the ones to remove
+		kept := make(map[string]interface{})
+		removed := make(map[string]interface{})
+		for k, id := range or["uuids"].(map[string]interface{}) {
+			if k != "%" {
+				removed[k] = id
+			}
+		}
+		for _, unit := range wanted {
+			if id, ok := removed[unit]; ok {
+				kept[unit] = id
+				delete(removed, unit)
+			}
+		}
+
+		nr["uuids"] = kept
+		or["uuids"] = removed
+
+		pairs = append(pairs, [2]map[string]interface{}{or, nr})
+	}
+
+	return pairs
+}
+
+// ruleUnits returns the keys used in the uuids map for a firewall or ACL rule.
+func ruleUnits(rule map[string]interface{}) []string {
+	rule = withRuleService(rule)
+
+	switch protocol := strings.ToLower(rule["protocol"].(string)); protocol {
+	case "icmp", "all":
+		return []string{protocol}
+	}
+
+	var units []string
+	if ps, ok := rule["ports"].(*schema.Set); ok {
+		for _, port := range ps.List() {
+			units = append(units, port.(string))
+		}
+	}
+
+	return units
+}
+
+// sortedCIDRs returns a comma separated CIDR list in a stable order, so it
+// can be used to group rules by their CIDR list.
+func sortedCIDRs(cidrList string) string {
+	cidrs := strings.Split(cidrList, ",")
+	sort.Strings(cidrs)
+	return strings.Join(cidrs, ",")
+}
+
 type ResourceWithConfigure struct {
 	client *cloudstack.CloudStackClient
 }
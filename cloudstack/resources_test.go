@@ -0,0 +1,70 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPairRules(t *testing.T) {
+	rule := func(cidr string, ports []interface{}, uuids map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"cidr_list": schema.NewSet(schema.HashString, []interface{}{cidr}),
+			"protocol":  "tcp",
+			"icmp_type": 0,
+			"icmp_code": 0,
+			"ports":     schema.NewSet(schema.HashString, ports),
+			"uuids":     uuids,
+		}
+	}
+
+	or := rule("10.0.0.0/8", []interface{}{"80", "443"}, map[string]interface{}{
+		"80":  "uuid-80",
+		"443": "uuid-443",
+	})
+	nr := rule("10.0.0.0/8", []interface{}{"443", "8080"}, map[string]interface{}{})
+	other := rule("192.168.0.0/16", []interface{}{"22"}, map[string]interface{}{})
+
+	ors := resourceCloudStackFirewall().Schema["rule"].ZeroValue().(*schema.Set)
+	ors.Add(or)
+	nrs := resourceCloudStackFirewall().Schema["rule"].ZeroValue().(*schema.Set)
+	nrs.Add(nr)
+	nrs.Add(other)
+
+	pairs := pairRules(ors, nrs, firewallRuleIdentity, ruleUnits)
+	if len(pairs) != 1 {
+		t.Fatalf("expected 1 pair, got %d", len(pairs))
+	}
+
+	if !reflect.DeepEqual(nr["uuids"], map[string]interface{}{"443": "uuid-443"}) {
+		t.Fatalf("bad kept uuids: %#v", nr["uuids"])
+	}
+
+	if !reflect.DeepEqual(or["uuids"], map[string]interface{}{"80": "uuid-80"}) {
+		t.Fatalf("bad removed uuids: %#v", or["uuids"])
+	}
+
+	if len(other["uuids"].(map[string]interface{})) != 0 {
+		t.Fatalf("unpaired rule should not get any uuids: %#v", other["uuids"])
+	}
+}
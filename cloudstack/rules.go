@@ -0,0 +1,290 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ruleEntry is a single unit (one source and one port range, ICMP type or
+// protocol) of a rule block. Rule blocks are broken down into entries so they
+// can be validated and compared with each other at plan time.
+type ruleEntry struct {
+	action   string
+	traffic  string
+	protocol string
+	source   string
+	network  *net.IPNet
+	dest     string
+	unit     string
+	start    int
+	end      int
+	icmpType int
+	icmpCode int
+	number   int
+}
+
+func (e *ruleEntry) String() string {
+	s := fmt.Sprintf("%s %s %s", e.action, e.traffic, e.protocol)
+	if e.source != "" {
+		s += " for " + e.source
+	}
+	if e.dest != "" {
+		s += " to " + e.dest
+	}
+
+	switch e.protocol {
+	case "tcp", "udp":
+		s += " port " + rulePort(e.start, e.end)
+	case "icmp":
+		s += fmt.Sprintf(" type %d code %d", e.icmpType, e.icmpCode)
+	}
+
+	if e.number > 0 {
+		s = fmt.Sprintf("#%d (%s)", e.number, s)
+	}
+
+	return s
+}
+
+// covers returns true if all traffic matched by entry b is also matched by
+// entry a.
+func (a *ruleEntry) covers(b *ruleEntry) bool {
+	if a.traffic != b.traffic || a.dest != b.dest {
+		return false
+	}
+
+	if a.protocol != "all" && a.protocol != b.protocol {
+		return false
+	}
+
+	if a.network == nil || b.network == nil {
+		if a.source != b.source {
+			return false
+		}
+	} else {
+		aOnes, aBits := a.network.Mask.Size()
+		bOnes, bBits := b.network.Mask.Size()
+		if aBits != bBits || aOnes > bOnes || !a.network.Contains(b.network.IP) {
+			return false
+		}
+	}
+
+	switch a.protocol {
+	case "tcp", "udp":
+		return a.start <= b.start && b.end <= a.end
+	case "icmp":
+		return (a.icmpType == -1 || a.icmpType == b.icmpType) &&
+			(a.icmpCode == -1 || a.icmpCode == b.icmpCode)
+	}
+
+	return true
+}
+
+// parseRuleEntries parses the CIDRs and ports of a rule block and breaks it
+// down into entries, after expanding any referenced service. The traffic type
+// is used when the rule itself has none.
+func parseRuleEntries(rule map[string]interface{}, traffic string) ([]*ruleEntry, error) {
+	if err := verifyRuleService(rule); err != nil {
+		return nil, err
+	}
+
+	rule, err := expandRuleService(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	base := ruleEntry{
+		action:   "allow",
+		traffic:  traffic,
+		protocol: strings.ToLower(rule["protocol"].(string)),
+	}
+
+	if action, ok := rule["action"].(string); ok && action != "" {
+		base.action = strings.ToLower(action)
+	}
+	if traffic, ok := rule["traffic_type"].(string); ok && traffic != "" {
+		base.traffic = strings.ToLower(traffic)
+	}
+
+	if dests, ok := rule["dest_cidr_list"].(*schema.Set); ok && dests.Len() > 0 {
+		var cidrs []string
+		for _, cidr := range dests.List() {
+			if _, _, err := net.ParseCIDR(cidr.(string)); err != nil {
+				return nil, fmt.Errorf("%q is not a valid CIDR", cidr.(string))
+			}
+			cidrs = append(cidrs, cidr.(string))
+		}
+		sort.Strings(cidrs)
+		base.dest = strings.Join(cidrs, ",")
+	}
+
+	var sources []ruleEntry
+	if cidrs, ok := rule["cidr_list"].(*schema.Set); ok {
+		for _, cidr := range cidrs.List() {
+			_, network, err := net.ParseCIDR(cidr.(string))
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid CIDR", cidr.(string))
+			}
+
+			e := base
+			e.source = cidr.(string)
+			e.network = network
+			sources = append(sources, e)
+		}
+	}
+	if groups, ok := rule["user_security_group_list"].(*schema.Set); ok {
+		for _, group := range groups.List() {
+			e := base
+			e.source = group.(string)
+			sources = append(sources, e)
+		}
+	}
+	if len(sources) == 0 {
+		sources = append(sources, base)
+	}
+
+	var entries []*ruleEntry
+	add := func(unit string, update func(*ruleEntry)) {
+		for _, source := range sources {
+			e := source
+			e.unit = unit
+			update(&e)
+			entries = append(entries, &e)
+		}
+	}
+
+	switch base.protocol {
+	case "tcp", "udp":
+		ports, ok := rule["ports"].(*schema.Set)
+		if !ok {
+			break
+		}
+
+		for _, port := range ports.List() {
+			m := splitPorts.FindStringSubmatch(port.(string))
+			if m == nil {
+				return nil, fmt.Errorf(
+					"%q is not a valid port value. Valid options are '80' or '80-90'", port.(string))
+			}
+
+			start, _ := strconv.Atoi(m[1])
+			end := start
+			if m[2] != "" {
+				end, _ = strconv.Atoi(m[2])
+			}
+
+			if start < 1 || end > 65535 || start > end {
+				return nil, fmt.Errorf(
+					"%q is not a valid port range, ports must be between 1 and 65535 "+
+						"and the start port cannot be higher than the end port", port.(string))
+			}
+
+			add(port.(string), func(e *ruleEntry) {
+				e.start = start
+				e.end = end
+			})
+		}
+	case "icmp":
+		icmpType, _ := rule["icmp_type"].(int)
+		icmpCode, _ := rule["icmp_code"].(int)
+		add("icmp", func(e *ruleEntry) {
+			e.icmpType = icmpType
+			e.icmpCode = icmpCode
+		})
+	default:
+		add(base.protocol, func(e *ruleEntry) {})
+	}
+
+	return entries, nil
+}
+
+// parseRuleSetEntries parses all rule blocks of the given rule set.
+func parseRuleSetEntries(d *schema.ResourceDiff, key string, traffic string) ([]*ruleEntry, error) {
+	var entries []*ruleEntry
+
+	for _, rule := range d.Get(key).(*schema.Set).List() {
+		es, err := parseRuleEntries(rule.(map[string]interface{}), traffic)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, es...)
+	}
+
+	return entries, nil
+}
+
+// checkShadowedRules returns an error if an entry is defined more than once,
+// and logs a warning for every entry that is fully covered by another entry.
+// If both entries are numbered only the entry with the lowest number can
+// shadow the other one, otherwise only entries with the same action are
+// compared as their order is unknown.
+func checkShadowedRules(resource string, entries []*ruleEntry) error {
+	for i, a := range entries {
+		for j, b := range entries {
+			if i == j || !a.covers(b) {
+				continue
+			}
+
+			ordered := a.number > 0 && b.number > 0 && a.number != b.number
+
+			if b.covers(a) && !ordered {
+				if i < j && a.action == b.action {
+					return fmt.Errorf("%s: rule %s is defined more than once", resource, b)
+				}
+				continue
+			}
+
+			switch {
+			case ordered && a.number < b.number:
+				log.Printf(
+					"[WARN] %s: rule %s will never match as it is shadowed by rule %s", resource, b, a)
+			case !ordered && a.action == b.action:
+				log.Printf(
+					"[WARN] %s: rule %s is redundant as it is fully covered by rule %s", resource, b, a)
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyRuleSet parses and validates the rules of the given rule set, rejects
+// duplicate rules and warns about shadowed rules. Nothing is checked while the rule set
+// still contains values that are only known after apply.
+func verifyRuleSet(d *schema.ResourceDiff, resource string, key string, traffic string) error {
+	if !d.NewValueKnown(key) {
+		return nil
+	}
+
+	entries, err := parseRuleSetEntries(d, key, traffic)
+	if err != nil {
+		return err
+	}
+
+	return checkShadowedRules(resource, entries)
+}
@@ -0,0 +1,142 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testRule(protocol string, cidrs []string, ports []string) map[string]interface{} {
+	rule := map[string]interface{}{
+		"protocol":  protocol,
+		"cidr_list": schema.NewSet(schema.HashString, nil),
+		"ports":     schema.NewSet(schema.HashString, nil),
+		"icmp_type": -1,
+		"icmp_code": -1,
+	}
+	for _, cidr := range cidrs {
+		rule["cidr_list"].(*schema.Set).Add(cidr)
+	}
+	for _, port := range ports {
+		rule["ports"].(*schema.Set).Add(port)
+	}
+	return rule
+}
+
+func TestParseRuleEntries(t *testing.T) {
+	cases := []struct {
+		Rule    map[string]interface{}
+		Entries int
+		Error   bool
+	}{
+		{testRule("tcp", []string{"10.0.0.0/8", "192.168.0.0/16"}, []string{"80", "1000-2000"}), 4, false},
+		{testRule("icmp", []string{"0.0.0.0/0"}, nil), 1, false},
+		{testRule("all", []string{"0.0.0.0/0"}, nil), 1, false},
+		{testRule("tcp", []string{"10.0.0.300/8"}, []string{"80"}), 0, true},
+		{testRule("tcp", []string{"10.0.0.0"}, []string{"80"}), 0, true},
+		{testRule("tcp", []string{"10.0.0.0/8"}, []string{"80-"}), 0, true},
+		{testRule("udp", []string{"10.0.0.0/8"}, []string{"90-80"}), 0, true},
+		{testRule("udp", []string{"10.0.0.0/8"}, []string{"0"}), 0, true},
+		{testRule("udp", []string{"10.0.0.0/8"}, []string{"65536"}), 0, true},
+	}
+
+	for i, tc := range cases {
+		entries, err := parseRuleEntries(tc.Rule, "ingress")
+		if (err != nil) != tc.Error {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if len(entries) != tc.Entries {
+			t.Fatalf("%d: expected %d entries, got %d", i, tc.Entries, len(entries))
+		}
+	}
+}
+
+func TestRuleEntryCovers(t *testing.T) {
+	entry := func(rule map[string]interface{}, action string) *ruleEntry {
+		rule["action"] = action
+		entries, err := parseRuleEntries(rule, "ingress")
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("failed to parse rule %#v: %v", rule, err)
+		}
+		return entries[0]
+	}
+
+	allowAll := entry(testRule("all", []string{"0.0.0.0/0"}, nil), "allow")
+	wide := entry(testRule("tcp", []string{"10.0.0.0/8"}, []string{"1-1024"}), "allow")
+	narrow := entry(testRule("tcp", []string{"10.1.0.0/16"}, []string{"80"}), "deny")
+	other := entry(testRule("udp", []string{"10.1.0.0/16"}, []string{"80"}), "allow")
+	outside := entry(testRule("tcp", []string{"172.16.0.0/12"}, []string{"80"}), "allow")
+	ping := entry(testRule("icmp", []string{"10.0.0.0/8"}, nil), "allow")
+
+	cases := []struct {
+		A, B   *ruleEntry
+		Covers bool
+	}{
+		{allowAll, narrow, true},
+		{allowAll, ping, true},
+		{narrow, allowAll, false},
+		{wide, narrow, true},
+		{narrow, wide, false},
+		{wide, other, false},
+		{wide, outside, false},
+		{ping, wide, false},
+	}
+
+	for i, tc := range cases {
+		if covers := tc.A.covers(tc.B); covers != tc.Covers {
+			t.Fatalf("%d: expected %s covers %s to be %t", i, tc.A, tc.B, tc.Covers)
+		}
+	}
+}
+
+func TestCheckShadowedRules(t *testing.T) {
+	entries := func(rules ...map[string]interface{}) []*ruleEntry {
+		var es []*ruleEntry
+		for _, rule := range rules {
+			rule["action"] = "allow"
+			e, err := parseRuleEntries(rule, "ingress")
+			if err != nil {
+				t.Fatalf("failed to parse rule %#v: %v", rule, err)
+			}
+			es = append(es, e...)
+		}
+		return es
+	}
+
+	// A port that is defined in two rules is a duplicate
+	dup := entries(
+		testRule("tcp", []string{"10.0.0.0/8"}, []string{"80", "443"}),
+		testRule("tcp", []string{"10.0.0.0/8"}, []string{"80"}),
+	)
+	if err := checkShadowedRules("test", dup); err == nil {
+		t.Fatalf("expected an error for a duplicate rule")
+	}
+
+	// A rule that is covered by another rule is only a warning
+	covered := entries(
+		testRule("tcp", []string{"10.0.0.0/8"}, []string{"1-1024"}),
+		testRule("tcp", []string{"10.1.0.0/16"}, []string{"80"}),
+	)
+	if err := checkShadowedRules("test", covered); err != nil {
+		t.Fatalf("unexpected error for a covered rule: %s", err)
+	}
+}
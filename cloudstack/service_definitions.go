@@ -0,0 +1,247 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type serviceDefinition struct {
+	protocol    string
+	ports       []string
+	description string
+}
+
+// serviceDefinitions contains the built-in, well-known services that can be
+// referenced by the rule blocks of the firewall, network ACL and security group
+// rule resources.
+var serviceDefinitions = map[string]serviceDefinition{
+	"dns":        {"udp", []string{"53"}, "Domain Name System"},
+	"dns-tcp":    {"tcp", []string{"53"}, "Domain Name System over TCP"},
+	"http":       {"tcp", []string{"80"}, "Hypertext Transfer Protocol"},
+	"http-alt":   {"tcp", []string{"8080"}, "Alternative HTTP port"},
+	"https":      {"tcp", []string{"443"}, "HTTP over TLS"},
+	"imap":       {"tcp", []string{"143"}, "Internet Message Access Protocol"},
+	"imaps":      {"tcp", []string{"993"}, "IMAP over TLS"},
+	"kubernetes": {"tcp", []string{"6443"}, "Kubernetes API server"},
+	"ldap":       {"tcp", []string{"389"}, "Lightweight Directory Access Protocol"},
+	"ldaps":      {"tcp", []string{"636"}, "LDAP over TLS"},
+	"memcached":  {"tcp", []string{"11211"}, "Memcached"},
+	"mongodb":    {"tcp", []string{"27017"}, "MongoDB"},
+	"mssql":      {"tcp", []string{"1433"}, "Microsoft SQL Server"},
+	"mysql":      {"tcp", []string{"3306"}, "MySQL and MariaDB"},
+	"ntp":        {"udp", []string{"123"}, "Network Time Protocol"},
+	"pop3":       {"tcp", []string{"110"}, "Post Office Protocol"},
+	"pop3s":      {"tcp", []string{"995"}, "POP3 over TLS"},
+	"postgres":   {"tcp", []string{"5432"}, "PostgreSQL"},
+	"rdp":        {"tcp", []string{"3389"}, "Remote Desktop Protocol"},
+	"redis":      {"tcp", []string{"6379"}, "Redis"},
+	"smtp":       {"tcp", []string{"25"}, "Simple Mail Transfer Protocol"},
+	"smtps":      {"tcp", []string{"465"}, "SMTP over TLS"},
+	"snmp":       {"udp", []string{"161"}, "Simple Network Management Protocol"},
+	"ssh":        {"tcp", []string{"22"}, "Secure Shell"},
+	"submission": {"tcp", []string{"587"}, "Mail submission"},
+	"web":        {"tcp", []string{"80", "443"}, "HTTP and HTTPS"},
+}
+
+// customServiceDefinitions contains the services defined by the service
+// blocks of the provider configuration.
+var (
+	customServiceDefinitions   = make(map[string]serviceDefinition)
+	customServiceDefinitionsMu sync.RWMutex
+)
+
+// setCustomServiceDefinitions adds the services defined in the provider
+// configuration. Services defined by other provider configurations are kept,
+// so every provider alias can define its own services.
+func setCustomServiceDefinitions(services []interface{}) error {
+	defs := make(map[string]serviceDefinition, len(services))
+	for _, service := range services {
+		service := service.(map[string]interface{})
+		name := service["name"].(string)
+
+		if _, ok := serviceDefinitions[name]; ok {
+			return fmt.Errorf("Service %q is a built-in service and cannot be redefined", name)
+		}
+		if _, ok := defs[name]; ok {
+			return fmt.Errorf("Service %q is defined more than once", name)
+		}
+
+		var ports []string
+		for _, port := range service["ports"].(*schema.Set).List() {
+			if !splitPorts.MatchString(port.(string)) {
+				return fmt.Errorf("Port %q of service %q is not a valid port or port range", port, name)
+			}
+			ports = append(ports, port.(string))
+		}
+		sort.Strings(ports)
+
+		defs[name] = serviceDefinition{service["protocol"].(string), ports, service["description"].(string)}
+	}
+
+	customServiceDefinitionsMu.Lock()
+	defer customServiceDefinitionsMu.Unlock()
+
+	for name, def := range defs {
+		if current, ok := customServiceDefinitions[name]; ok && !reflect.DeepEqual(current, def) {
+			return fmt.Errorf("Service %q is already defined differently by another provider configuration", name)
+		}
+	}
+	for name, def := range defs {
+		customServiceDefinitions[name] = def
+	}
+
+	return nil
+}
+
+// lookupServiceDefinition returns the built-in or custom service definition
+// with the given name.
+func lookupServiceDefinition(name string) (serviceDefinition, bool) {
+	if def, ok := serviceDefinitions[name]; ok {
+		return def, true
+	}
+
+	customServiceDefinitionsMu.RLock()
+	defer customServiceDefinitionsMu.RUnlock()
+
+	def, ok := customServiceDefinitions[name]
+	return def, ok
+}
+
+// serviceNames returns the sorted names of all service definitions.
+func serviceNames() []string {
+	var names []string
+	for name := range serviceDefinitions {
+		names = append(names, name)
+	}
+
+	customServiceDefinitionsMu.RLock()
+	for name := range customServiceDefinitions {
+		names = append(names, name)
+	}
+	customServiceDefinitionsMu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// getServiceDefinition returns the service definition with the given name.
+func getServiceDefinition(name string) (serviceDefinition, error) {
+	def, ok := lookupServiceDefinition(name)
+	if !ok {
+		return def, fmt.Errorf(
+			"%q is not a known service. Valid options are: %s", name, strings.Join(serviceNames(), ", "))
+	}
+	return def, nil
+}
+
+// expandRuleService returns a copy of the rule with the protocol and ports of
+// its service definition filled in. The copy shares the uuids map of the rule.
+// Rules that don't reference a service are returned as is.
+func expandRuleService(rule map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := rule["service"].(string)
+	if name == "" {
+		return rule, nil
+	}
+
+	def, err := getServiceDefinition(name)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make(map[string]interface{}, len(rule))
+	for k, v := range rule {
+		expanded[k] = v
+	}
+
+	ports := &schema.Set{F: schema.HashString}
+	for _, port := range def.ports {
+		ports.Add(port)
+	}
+
+	expanded["protocol"] = def.protocol
+	expanded["ports"] = ports
+
+	return expanded, nil
+}
+
+// withRuleService is like expandRuleService, but returns the rule as is when
+// its service is unknown. Unknown services are rejected during plan, so this
+// is only used where errors cannot be returned.
+func withRuleService(rule map[string]interface{}) map[string]interface{} {
+	if expanded, err := expandRuleService(rule); err == nil {
+		return expanded
+	}
+	return rule
+}
+
+// collapseRuleService reverses expandRuleService before a rule is stored, so
+// the stored rule matches the configured one. When not all ports of the service
+// exist, the existing ports are kept so the missing ones show up in the diff.
+func collapseRuleService(rule map[string]interface{}) map[string]interface{} {
+	name, _ := rule["service"].(string)
+	def, ok := lookupServiceDefinition(name)
+	if !ok {
+		return rule
+	}
+
+	collapsed := make(map[string]interface{}, len(rule))
+	for k, v := range rule {
+		collapsed[k] = v
+	}
+
+	ports := &schema.Set{F: schema.HashString}
+	if found, ok := rule["ports"].(*schema.Set); ok && found.Len() < len(def.ports) {
+		ports = found
+	}
+
+	collapsed["protocol"] = ""
+	collapsed["ports"] = ports
+
+	return collapsed
+}
+
+// verifyRuleService makes sure a rule either references a service or sets a
+// protocol itself.
+func verifyRuleService(rule map[string]interface{}) error {
+	name, _ := rule["service"].(string)
+	if name == "" {
+		if rule["protocol"].(string) == "" {
+			return fmt.Errorf("Parameter protocol is required when no service is set")
+		}
+		return nil
+	}
+
+	if _, err := getServiceDefinition(name); err != nil {
+		return err
+	}
+
+	if ports, ok := rule["ports"].(*schema.Set); rule["protocol"].(string) != "" || (ok && ports.Len() > 0) {
+		return fmt.Errorf("Parameter service %q cannot be combined with protocol or ports", name)
+	}
+
+	return nil
+}
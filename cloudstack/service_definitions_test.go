@@ -0,0 +1,164 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandRuleService(t *testing.T) {
+	uuids := map[string]interface{}{"80": "uuid-80"}
+	rule := map[string]interface{}{
+		"service":  "web",
+		"protocol": "",
+		"ports":    &schema.Set{F: schema.HashString},
+		"uuids":    uuids,
+	}
+
+	expanded, err := expandRuleService(rule)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expanded["protocol"].(string) != "tcp" {
+		t.Fatalf("expected protocol tcp, got %s", expanded["protocol"])
+	}
+	if ports := expanded["ports"].(*schema.Set); ports.Len() != 2 || !ports.Contains("80") || !ports.Contains("443") {
+		t.Fatalf("expected ports 80 and 443, got %v", ports.List())
+	}
+	if rule["protocol"].(string) != "" || rule["ports"].(*schema.Set).Len() != 0 {
+		t.Fatalf("expanding a rule should not modify the rule itself")
+	}
+
+	// The expanded rule shares the UUIDs of the rule
+	expanded["uuids"].(map[string]interface{})["443"] = "uuid-443"
+	if _, ok := uuids["443"]; !ok {
+		t.Fatalf("expected the expanded rule to share the uuids map")
+	}
+
+	// All ports exist, so the rule collapses to its configured form
+	collapsed := collapseRuleService(expanded)
+	if collapsed["protocol"].(string) != "" || collapsed["ports"].(*schema.Set).Len() != 0 {
+		t.Fatalf("expected a collapsed rule without protocol and ports, got %#v", collapsed)
+	}
+
+	// Only some of the ports exist, so they are kept to cause a diff
+	partial := &schema.Set{F: schema.HashString}
+	partial.Add("80")
+	expanded["ports"] = partial
+
+	collapsed = collapseRuleService(expanded)
+	if ports := collapsed["ports"].(*schema.Set); ports.Len() != 1 || !ports.Contains("80") {
+		t.Fatalf("expected the existing port 80 to be kept, got %v", ports.List())
+	}
+}
+
+func TestVerifyRuleService(t *testing.T) {
+	ports := &schema.Set{F: schema.HashString}
+	ports.Add("22")
+
+	cases := []struct {
+		Rule  map[string]interface{}
+		Error bool
+	}{
+		{map[string]interface{}{"service": "ssh", "protocol": ""}, false},
+		{map[string]interface{}{"service": "", "protocol": "tcp", "ports": ports}, false},
+		{map[string]interface{}{"service": "gopher", "protocol": ""}, true},
+		{map[string]interface{}{"service": "ssh", "protocol": "tcp"}, true},
+		{map[string]interface{}{"service": "ssh", "protocol": "", "ports": ports}, true},
+		{map[string]interface{}{"service": "", "protocol": ""}, true},
+	}
+
+	for i, tc := range cases {
+		if err := verifyRuleService(tc.Rule); (err != nil) != tc.Error {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func testServiceBlock(name, protocol string, ports ...string) map[string]interface{} {
+	set := &schema.Set{F: schema.HashString}
+	for _, port := range ports {
+		set.Add(port)
+	}
+	return map[string]interface{}{
+		"name":        name,
+		"protocol":    protocol,
+		"ports":       set,
+		"description": "",
+	}
+}
+
+func TestSetCustomServiceDefinitions(t *testing.T) {
+	t.Cleanup(func() {
+		customServiceDefinitionsMu.Lock()
+		defer customServiceDefinitionsMu.Unlock()
+		delete(customServiceDefinitions, "test-app")
+		delete(customServiceDefinitions, "test-metrics")
+	})
+
+	err := setCustomServiceDefinitions([]interface{}{
+		testServiceBlock("test-app", "tcp", "8443", "9000-9010"),
+		testServiceBlock("test-metrics", "udp", "8125"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	def, err := getServiceDefinition("test-app")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if def.protocol != "tcp" || len(def.ports) != 2 {
+		t.Fatalf("unexpected service definition: %#v", def)
+	}
+
+	// Custom services can be used in rules like the built-in services
+	rule := map[string]interface{}{"service": "test-metrics", "protocol": ""}
+	if err := verifyRuleService(rule); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expanded, err := expandRuleService(rule)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expanded["protocol"].(string) != "udp" || !expanded["ports"].(*schema.Set).Contains("8125") {
+		t.Fatalf("expected protocol udp and port 8125, got %#v", expanded)
+	}
+
+	// Defining the same service again, for example by a provider alias, is fine
+	if err := setCustomServiceDefinitions([]interface{}{testServiceBlock("test-app", "tcp", "8443", "9000-9010")}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := [][]interface{}{
+		{testServiceBlock("ssh", "tcp", "2222")},
+		{testServiceBlock("test-other", "tcp", "80"), testServiceBlock("test-other", "tcp", "81")},
+		{testServiceBlock("test-other", "tcp", "http")},
+		{testServiceBlock("test-app", "tcp", "8080")},
+	}
+	for i, services := range cases {
+		if err := setCustomServiceDefinitions(services); err == nil {
+			t.Fatalf("%d: expected an error", i)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+//
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+
+package cloudstack
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	content := strings.Repeat("terraform", 1000)
+
+	path := filepath.Join(t.TempDir(), "template.qcow2")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(checksum, "{SHA-256}") || len(checksum) != len("{SHA-256}")+64 {
+		t.Fatalf("unexpected checksum: %s", checksum)
+	}
+
+	var received string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-signature") != "signature" ||
+			r.Header.Get("X-metadata") != "metadata" ||
+			r.Header.Get("X-expires") != "expires" {
+			http.Error(w, "missing upload headers", http.StatusForbidden)
+			return
+		}
+
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+
+		if h.Filename != "template.qcow2" {
+			http.Error(w, "unexpected filename "+h.Filename, http.StatusBadRequest)
+			return
+		}
+
+		b, _ := io.ReadAll(f)
+		received = string(b)
+	}))
+	defer server.Close()
+
+	params := &uploadParams{
+		postURL:   server.URL,
+		expires:   "expires",
+		metadata:  "metadata",
+		signature: "signature",
+	}
+
+	if err := uploadFile(path, params); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if received != content {
+		t.Fatalf("uploaded content does not match the file content")
+	}
+
+	params.signature = "invalid"
+	if err := uploadFile(path, params); err == nil {
+		t.Fatalf("expected an error when the upload is rejected")
+	}
+}